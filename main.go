@@ -3,8 +3,11 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joshmue/scs-status-page-openapi/pkg/api"
 	"github.com/joshmue/scs-status-page-openapi/pkg/server"
@@ -13,20 +16,128 @@ import (
 	"github.com/labstack/gommon/log"
 	"github.com/shurcooL/githubv4"
 	"golang.org/x/oauth2"
+	"gopkg.in/yaml.v3"
 )
 
 func main() {
+	configFile := flag.String("config", "", `YAML file setting flags by name, e.g. "fields.phase: Phase"; nested keys are joined by ".", lists by ",". Flags given on the command line take precedence, followed by environment variables like SCS_STATUS_PAGE_FIELDS_PHASE`)
 	addr := flag.String("addr", ":3000", "address to listen on")
 	projectOwner := flag.String("github.project.owner", "joshmue", "user owning the project")
 	projectOwnerIsOrg := flag.Bool("github.project.owner.is-org", false, "sets whether the owner of the github project is an org instead of an user")
 	projectNumber := flag.Int64("github.project.number", 1, "project number")
-	impactTypeList := flag.String("impacttypes", "performance-degration,connectivity-issues", `","-seperated list of impact types`)
+	impactTypeList := flag.String("impacttypes", "performance-degration,connectivity-issues", `","-seperated list of impact types, ordered from least to most severe`)
 	lastPhase := flag.String("last-phase", "Done", "last phase of incidents")
-	flag.Parse()
+	fieldsPhase := flag.String("fields.phase", server.DefaultFieldNames.Phase, "name of the single select field holding the phase of incidents")
+	fieldsImpactType := flag.String("fields.impact-type", server.DefaultFieldNames.ImpactType, "name of the single select field holding the impact type of incidents")
+	fieldsBeganAt := flag.String("fields.began-at", server.DefaultFieldNames.BeganAt, "name of the text or date field holding when incidents began")
+	fieldsEndedAt := flag.String("fields.ended-at", server.DefaultFieldNames.EndedAt, "name of the text or date field holding when incidents ended")
+	fieldsBeganAtTime := flag.String("fields.began-at-time", "", `name of the text field holding the time of day incidents began, if -fields.began-at only holds the date; "" if there is none`)
+	fieldsEndedAtTime := flag.String("fields.ended-at-time", "", `name of the text field holding the time of day incidents ended, if -fields.ended-at only holds the date; "" if there is none`)
+	fieldsLabels := flag.String("fields.labels", server.DefaultFieldNames.Labels, "name of the built-in field holding the labels of issues")
+	componentPrefix := flag.String("components.label-prefix", server.DefaultComponentPrefix, "prefix of labels marking components")
+	timeZone := flag.String("timezone", "UTC", "IANA time zone determining day boundaries")
+	timestampTimeZone := flag.String("timestamps.timezone", "", `IANA time zone interpreting date fields and timestamps without offset; "" for the one of -timezone`)
+	statuspagePageName := flag.String("statuspage.page.name", "SCS Status Page", "page name reported by the Statuspage.io compatible API")
+	statuspagePageURL := flag.String("statuspage.page.url", "", "page URL reported by the Statuspage.io compatible API")
+	statuspageImpactTypes := flag.String("statuspage.impacttypes", "performance-degration=degraded_performance,connectivity-issues=major_outage", `","-seperated list of "<impact type>=<component status>" mappings for the Statuspage.io compatible API`)
+	maintenanceImpactType := flag.String("maintenance.impacttype", "", `impact type marking items as maintenance windows, e.g. "maintenance"; "" disables maintenance windows`)
+	maintenanceInProgressPhase := flag.String("maintenance.phase.in-progress", "In Progress", "phase maintenance windows are moved to once they start")
+	maintenanceInterval := flag.Duration("maintenance.interval", time.Minute, "interval for moving maintenance windows between phases")
+	availabilityWeights := flag.String("availability.weights", "performance-degration=0.5,connectivity-issues=1", `","-seperated list of "<impact type>=<weight>" mappings setting how much incidents count as downtime`)
+	availabilityExcludeMaintenance := flag.Bool("availability.exclude-maintenance", true, "exclude maintenance windows from availability calculations by default")
+	snapshotInterval := flag.Duration("snapshot.interval", 30*time.Second, "interval for polling incidents and components to derive events")
+	webhookStateFile := flag.String("webhooks.state-file", "", "file persisting webhook subscriptions; if empty, they are lost on restart")
+	publicURL := flag.String("public-url", "http://localhost:3000", "URL the server is reachable at, used for links in notifications and feeds")
+	emailSMTPAddr := flag.String("email.smtp.addr", "", `"<host>:<port>" of the SMTP server for email subscriptions; "" disables them`)
+	emailSMTPUsername := flag.String("email.smtp.username", "", "username for the SMTP server; the password is read from SMTP_PASSWORD")
+	emailFrom := flag.String("email.from", "status@localhost", "sender address of emails")
+	emailStateFile := flag.String("email.state-file", "", "file persisting email subscribers; if empty, they are lost on restart")
+	chatChannelsFile := flag.String("chat.channels-file", "", `JSON file listing Slack and Matrix incoming webhooks as {"format": "slack"|"matrix", "url": ..., "components": [...]}; "" disables chat notifications`)
+	alertmanagerRulesFile := flag.String("alertmanager.rules-file", "", `JSON file listing rules mapping alerts to incidents as {"matchers": {<label>: <value>}, "components": [...], "impactType": ...}; "" disables the Alertmanager receiver`)
+	alertmanagerStateFile := flag.String("alertmanager.state-file", "", "file persisting which alert opened which incident; if empty, it is lost on restart")
+	probesFile := flag.String("probes-file", "", `JSON file listing synthetic probes as {"name": ..., "component": ..., "type": "http"|"tcp"|"dns", "target": ..., "interval": "1m", "timeout": "10s", "failureThreshold": 3, "impactType": ...}; "" disables probes`)
+	probeStateFile := flag.String("probes.state-file", "", "file persisting which probe opened which incident; if empty, it is lost on restart")
+	heartbeatsFile := flag.String("heartbeats-file", "", `JSON file listing heartbeats as {"name": ..., "component": ..., "token": ..., "interval": "5m", "impactType": ...}; "" disables heartbeats`)
+	heartbeatStateFile := flag.String("heartbeats.state-file", "", "file persisting which heartbeat opened which incident; if empty, it is lost on restart")
+	pageThemeDir := flag.String("page.theme-dir", "", "directory with templates and a style.css overriding those of the HTML status page by file name")
+	cachingCacheControl := flag.String("caching.cache-control", "/components=no-cache;/components/:componentId=no-cache;/incidents=no-cache;/incident/:incidentId=no-cache;/phases=public, max-age=300;/impacttypes=public, max-age=300", `";"-seperated list of "<route>=<Cache-Control header>" mappings; listed routes receive ETags and answer conditional requests`)
+	cachingLastModified := flag.String("caching.last-modified", "/components,/components/:componentId,/incidents,/incident/:incidentId,/phases,/impacttypes", `","-seperated list of routes sending "Last-Modified" headers; listed routes receive ETags and answer conditional requests`)
+	githubTimeout := flag.Duration("github.timeout", 30*time.Second, "timeout of requests to the GitHub API; timed out requests respond with 504")
+	bootstrapProjectTitle := flag.String("bootstrap.project-title", "SCS Status Page", "bootstrap: title of the project created if the owner has none with the configured number")
+	bootstrapPhases := flag.String("bootstrap.phases", "Investigating,Identified,Monitoring,In Progress,Done", `bootstrap: ","-seperated list of phases, ordered; the last phase is appended if missing`)
+	bootstrapRepository := flag.String("bootstrap.repository", "", `bootstrap: "<owner>/<name>" of the repository to link to the project and create the component labels in`)
+	bootstrapComponents := flag.String("bootstrap.components", "", `bootstrap: ","-seperated list of components to create labels for`)
+	bootstrapDryRun := flag.Bool("bootstrap.dry-run", false, "bootstrap: only print the planned changes")
+	// "bootstrap" provisions the project instead of serving it, e.g. "<binary> bootstrap -bootstrap.dry-run"
+	bootstrap := len(os.Args) > 1 && os.Args[1] == "bootstrap"
+	if bootstrap {
+		flag.CommandLine.Parse(os.Args[2:])
+	} else {
+		flag.Parse()
+	}
+	if *configFile == "" {
+		*configFile = os.Getenv(configEnvName("config"))
+	}
+	if err := applyConfig(flag.CommandLine, *configFile); err != nil {
+		log.Fatal(err)
+	}
+
+	weights, err := parseWeights(*availabilityWeights)
+	if err != nil {
+		log.Fatal(err)
+	}
+	location, err := time.LoadLocation(*timeZone)
+	if err != nil {
+		log.Fatal(err)
+	}
+	timestampLocation := location
+	if *timestampTimeZone != "" {
+		timestampLocation, err = time.LoadLocation(*timestampTimeZone)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	alertRules := []server.AlertRule{}
+	if *alertmanagerRulesFile != "" {
+		alertRules, err = server.LoadAlertRules(*alertmanagerRulesFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	probes := []server.Probe{}
+	if *probesFile != "" {
+		probes, err = server.LoadProbes(*probesFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	heartbeats := []server.Heartbeat{}
+	if *heartbeatsFile != "" {
+		heartbeats, err = server.LoadHeartbeats(*heartbeatsFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	chatChannels := []server.ChatChannel{}
+	if *chatChannelsFile != "" {
+		chatChannels, err = server.LoadChatChannels(*chatChannelsFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	bootstrapConfig := server.BootstrapConfig{
+		ProjectTitle: *bootstrapProjectTitle,
+		Phases:       splitList(*bootstrapPhases),
+		Repository:   *bootstrapRepository,
+		Components:   splitList(*bootstrapComponents),
+		DryRun:       *bootstrapDryRun,
+	}
 
 	httpClient := oauth2.NewClient(context.Background(), oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: os.Getenv("GITHUB_TOKEN")},
 	))
+	httpClient.Transport = server.NewGithubMetricsTransport(httpClient.Transport)
+	httpClient.Timeout = *githubTimeout
 	server := &server.ServerImplementation{
 		GithubV4Client:    githubv4.NewClient(httpClient),
 		ProjectOwner:      *projectOwner,
@@ -34,9 +145,80 @@ func main() {
 		ProjectNumber:     *projectNumber,
 		ImpactTypes:       strings.Split(*impactTypeList, ","),
 		LastPhase:         *lastPhase,
+		Fields: server.FieldNames{
+			Phase:       *fieldsPhase,
+			ImpactType:  *fieldsImpactType,
+			BeganAt:     *fieldsBeganAt,
+			EndedAt:     *fieldsEndedAt,
+			Labels:      *fieldsLabels,
+			BeganAtTime: *fieldsBeganAtTime,
+			EndedAtTime: *fieldsEndedAtTime,
+		},
+		ComponentPrefix:   *componentPrefix,
+		TimeZone:          location,
+		TimestampTimeZone: timestampLocation,
+		Statuspage: server.StatuspageConfig{
+			PageName:         *statuspagePageName,
+			PageURL:          *statuspagePageURL,
+			ImpactTypeStatus: parseMapping(*statuspageImpactTypes),
+		},
+		Maintenance: server.MaintenanceConfig{
+			ImpactType:      *maintenanceImpactType,
+			InProgressPhase: *maintenanceInProgressPhase,
+		},
+		Availability: server.AvailabilityConfig{
+			ImpactTypeWeights:  weights,
+			ExcludeMaintenance: *availabilityExcludeMaintenance,
+		},
+		Webhooks: server.WebhookConfig{
+			StateFile: *webhookStateFile,
+		},
+		Email: server.EmailConfig{
+			SMTPAddr:  *emailSMTPAddr,
+			Username:  *emailSMTPUsername,
+			Password:  os.Getenv("SMTP_PASSWORD"),
+			From:      *emailFrom,
+			StateFile: *emailStateFile,
+		},
+		ChatChannels: chatChannels,
+		Alertmanager: server.AlertmanagerConfig{
+			Rules:     alertRules,
+			StateFile: *alertmanagerStateFile,
+		},
+		Probes:             probes,
+		ProbeStateFile:     *probeStateFile,
+		Heartbeats:         heartbeats,
+		HeartbeatStateFile: *heartbeatStateFile,
+		Page: server.PageConfig{
+			ThemeDir: *pageThemeDir,
+		},
+		Caching: server.CachingConfig{
+			Routes: parseCaching(*cachingCacheControl, *cachingLastModified),
+		},
+		PublicURL:  *publicURL,
+		AdminToken: os.Getenv("ADMIN_TOKEN"),
+	}
+
+	if err := server.ValidateImpactTypes(); err != nil {
+		log.Fatal(err)
+	}
+	if err := server.ValidateStatuspage(); err != nil {
+		log.Fatal(err)
+	}
+	if bootstrap {
+		if err := server.Bootstrap(context.Background(), bootstrapConfig, os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		if !*bootstrapDryRun {
+			if err := server.EnsureProjectConfiguration(); err != nil {
+				log.Fatal(err)
+			}
+		}
+		return
 	}
 
 	e := echo.New()
+	e.HTTPErrorHandler = server.HTTPErrorHandler
 	e.Logger.SetLevel(log.DEBUG)
 	e.Logger.Debugf("Obtaining Github Project ID...")
 	if err := server.FillProjectID(); err != nil {
@@ -44,12 +226,32 @@ func main() {
 	}
 	e.Logger.Debugf("Ensuring Github Project configuration meets expectations...")
 	if err := server.EnsureProjectConfiguration(); err != nil {
+		// Keep serving, so the problems can be inspected via /diagnostics; /readyz reports them
+		e.Logger.Errorf("Github Project configuration does not meet expectations: %s", err)
+	}
+
+	if err := server.LoadAlertIncidents(); err != nil {
+		e.Logger.Fatal(err)
+	}
+	if err := server.LoadPageTemplates(); err != nil {
 		e.Logger.Fatal(err)
 	}
 
 	e.Logger.Debugf("Registering handlers...")
 	e.Use(middleware.Logger())
+	e.Use(server.MetricsMiddleware)
+	e.Use(server.ConditionalGetMiddleware)
 	api.RegisterHandlers(e, server)
+	server.RegisterStatuspageHandlers(e)
+	server.RegisterFeedHandlers(e)
+	server.RegisterICalHandlers(e)
+	server.RegisterEventHandlers(e)
+	server.RegisterWebsocketHandlers(e)
+	server.RegisterMetricsHandlers(e)
+	server.RegisterHealthHandlers(e)
+	server.RegisterAlertmanagerHandlers(e)
+	server.RegisterPageHandlers(e)
+	server.RegisterBadgeHandlers(e)
 	e.GET("/openapi.json", func(c echo.Context) error {
 		swagger, err := api.GetSwagger()
 		if err != nil {
@@ -60,6 +262,184 @@ func main() {
 	})
 	e.GET("/swagger/", serveSwagger)
 
+	if server.Maintenance.ImpactType != "" {
+		e.Logger.Debugf("Starting maintenance transitions...")
+		go server.RunMaintenanceTransitions(context.Background(), e.Logger, *maintenanceInterval)
+	}
+
+	e.Logger.Debugf("Starting webhooks...")
+	go func() {
+		if err := server.RunWebhooks(context.Background(), e.Logger); err != nil {
+			e.Logger.Fatal(err)
+		}
+	}()
+
+	if server.Email.SMTPAddr != "" {
+		e.Logger.Debugf("Starting emails...")
+		go func() {
+			if err := server.RunEmails(context.Background(), e.Logger); err != nil {
+				e.Logger.Fatal(err)
+			}
+		}()
+	}
+
+	if len(server.ChatChannels) > 0 {
+		e.Logger.Debugf("Starting chat notifications...")
+		go server.RunChatNotifications(context.Background(), e.Logger)
+	}
+
+	if len(server.Probes) > 0 {
+		e.Logger.Debugf("Starting probes...")
+		go func() {
+			if err := server.RunProbes(context.Background(), e.Logger); err != nil {
+				e.Logger.Fatal(err)
+			}
+		}()
+	}
+
+	if len(server.Heartbeats) > 0 {
+		e.Logger.Debugf("Starting heartbeats...")
+		go func() {
+			if err := server.RunHeartbeats(context.Background(), e.Logger); err != nil {
+				e.Logger.Fatal(err)
+			}
+		}()
+	}
+
+	e.Logger.Debugf("Starting snapshots...")
+	go server.RunSnapshots(context.Background(), e.Logger, *snapshotInterval)
+
 	e.Logger.Debugf("Starting server...")
 	e.Logger.Fatal(e.Start(*addr))
 }
+
+// parseMapping parses a ","-seperated list of "<key>=<value>" pairs.
+func parseMapping(list string) map[string]string {
+	mapping := map[string]string{}
+	for _, pair := range strings.Split(list, ",") {
+		key, value, found := strings.Cut(pair, "=")
+		if !found {
+			continue
+		}
+		mapping[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return mapping
+}
+
+// parseWeights parses a ","-seperated list of "<key>=<float>" pairs.
+func parseWeights(list string) (map[string]float64, error) {
+	weights := map[string]float64{}
+	for key, value := range parseMapping(list) {
+		weight, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf(`invalid weight "%s" for "%s": %w`, value, key, err)
+		}
+		weights[key] = weight
+	}
+	return weights, nil
+}
+
+// parseCaching combines a ";"-seperated list of "<route>=<Cache-Control header>" pairs
+// and a ","-seperated list of routes sending "Last-Modified" headers.
+func parseCaching(cacheControl string, lastModified string) map[string]server.RouteCaching {
+	routes := map[string]server.RouteCaching{}
+	for _, pair := range strings.Split(cacheControl, ";") {
+		route, value, found := strings.Cut(pair, "=")
+		if !found {
+			continue
+		}
+		routes[strings.TrimSpace(route)] = server.RouteCaching{CacheControl: strings.TrimSpace(value)}
+	}
+	for _, route := range strings.Split(lastModified, ",") {
+		route = strings.TrimSpace(route)
+		if route == "" {
+			continue
+		}
+		config := routes[route]
+		config.LastModified = true
+		routes[route] = config
+	}
+	return routes
+}
+
+// splitList splits a ","-seperated list, ignoring empty items.
+func splitList(list string) []string {
+	items := []string{}
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// configEnvName returns the environment variable overriding a flag, e.g.
+// "SCS_STATUS_PAGE_FIELDS_PHASE" for "fields.phase".
+func configEnvName(flagName string) string {
+	return "SCS_STATUS_PAGE_" + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(flagName))
+}
+
+// flattenConfig collects the settings of a YAML document by flag name.
+func flattenConfig(prefix string, document map[string]interface{}, settings map[string]string) {
+	for key, value := range document {
+		name := key
+		if prefix != "" {
+			name = prefix + "." + key
+		}
+		switch value := value.(type) {
+		case map[string]interface{}:
+			flattenConfig(name, value, settings)
+		case []interface{}:
+			items := []string{}
+			for _, item := range value {
+				items = append(items, fmt.Sprint(item))
+			}
+			settings[name] = strings.Join(items, ",")
+		case nil:
+			settings[name] = ""
+		default:
+			settings[name] = fmt.Sprint(value)
+		}
+	}
+}
+
+// applyConfig sets flags not given on the command line from environment variables or,
+// failing that, from the YAML configuration file at path, if any.
+func applyConfig(flags *flag.FlagSet, path string) error {
+	settings := map[string]string{}
+	if path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		document := map[string]interface{}{}
+		if err := yaml.Unmarshal(content, &document); err != nil {
+			return fmt.Errorf("parsing %s: %w", path, err)
+		}
+		flattenConfig("", document, settings)
+	}
+	for name := range settings {
+		if flags.Lookup(name) == nil || name == "config" {
+			return fmt.Errorf(`unknown setting "%s" in %s`, name, path)
+		}
+	}
+	given := map[string]bool{"config": true}
+	flags.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+	})
+	var err error
+	flags.VisitAll(func(f *flag.Flag) {
+		if given[f.Name] || err != nil {
+			return
+		}
+		if value, ok := os.LookupEnv(configEnvName(f.Name)); ok {
+			err = flags.Set(f.Name, value)
+		} else if value, ok := settings[f.Name]; ok {
+			err = flags.Set(f.Name, value)
+		}
+		if err != nil {
+			err = fmt.Errorf(`setting "%s": %w`, f.Name, err)
+		}
+	})
+	return err
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApplyConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := `
+fields:
+  phase: Phase
+  labels: Etiketten
+github:
+  project:
+    number: 3
+impacttypes: [beeinträchtigt, ausfall]
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	phase := flags.String("fields.phase", "Status", "")
+	labels := flags.String("fields.labels", "Labels", "")
+	impactTypes := flags.String("impacttypes", "", "")
+	number := flags.Int("github.project.number", 1, "")
+	prefix := flags.String("component-prefix", "component:", "")
+	flags.String("config", "", "")
+	if err := flags.Parse([]string{"-fields.phase=Zustand"}); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(configEnvName("github.project.number"), "7")
+
+	if err := applyConfig(flags, path); err != nil {
+		t.Fatal(err)
+	}
+	if *phase != "Zustand" {
+		t.Errorf("expected the command line to take precedence; got %q", *phase)
+	}
+	if *number != 7 {
+		t.Errorf("expected the environment to take precedence over the file; got %d", *number)
+	}
+	if *labels != "Etiketten" || *impactTypes != "beeinträchtigt,ausfall" {
+		t.Errorf("expected settings of the file; got %q and %q", *labels, *impactTypes)
+	}
+	if *prefix != "component:" {
+		t.Errorf("expected defaults of settings missing in the file; got %q", *prefix)
+	}
+}
+
+func TestApplyConfigRejectsUnknownSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("fields:\n  phse: Phase\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.String("fields.phase", "Status", "")
+	if err := applyConfig(flags, path); err == nil {
+		t.Error("expected unknown settings to be rejected")
+	}
+}
+
+func TestConfigEnvName(t *testing.T) {
+	if name := configEnvName("maintenance.phase.in-progress"); name != "SCS_STATUS_PAGE_MAINTENANCE_PHASE_IN_PROGRESS" {
+		t.Errorf("got %s", name)
+	}
+}
@@ -15,10 +15,36 @@ import (
 	"time"
 
 	"github.com/deepmap/oapi-codegen/pkg/runtime"
+	openapi_types "github.com/deepmap/oapi-codegen/pkg/types"
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/labstack/echo/v4"
 )
 
+const (
+	BearerAuthScopes = "bearerAuth.Scopes"
+)
+
+// Defines values for MaintenanceStatus.
+const (
+	Completed  MaintenanceStatus = "completed"
+	InProgress MaintenanceStatus = "in-progress"
+	Scheduled  MaintenanceStatus = "scheduled"
+)
+
+// Defines values for ProbeType.
+const (
+	Dns  ProbeType = "dns"
+	Http ProbeType = "http"
+	Tcp  ProbeType = "tcp"
+)
+
+// Defines values for WebhookEventType.
+const (
+	IncidentCreated      WebhookEventType = "incident.created"
+	IncidentPhaseChanged WebhookEventType = "incident.phase_changed"
+	IncidentResolved     WebhookEventType = "incident.resolved"
+)
+
 // Component defines model for Component.
 type Component struct {
 	AffectedBy  []Id   `json:"affectedBy"`
@@ -27,9 +53,82 @@ type Component struct {
 	Labels      Labels `json:"labels"`
 }
 
+// ComponentAvailability defines model for ComponentAvailability.
+type ComponentAvailability struct {
+	// Availability Percentage of the time frame the component was available
+	Availability float64 `json:"availability"`
+	ComponentId  Id      `json:"componentId"`
+
+	// DowntimeSeconds Downtime within the time frame, weighted by impact type
+	DowntimeSeconds float64   `json:"downtimeSeconds"`
+	End             time.Time `json:"end"`
+
+	// ExcludedSeconds Time excluded from the time frame due to maintenance windows
+	ExcludedSeconds float64   `json:"excludedSeconds"`
+	Start           time.Time `json:"start"`
+}
+
+// ComponentHistory defines model for ComponentHistory.
+type ComponentHistory struct {
+	ComponentId Id            `json:"componentId"`
+	Days        []DailyStatus `json:"days"`
+}
+
+// ConfigurationProblem defines model for ConfigurationProblem.
+type ConfigurationProblem struct {
+	// Check Identifier of the failed check, e.g. "components" or "last-phase"
+	Check string `json:"check"`
+
+	// Hint How to fix the problem
+	Hint    *string `json:"hint,omitempty"`
+	Message string  `json:"message"`
+}
+
+// DailyStatus defines model for DailyStatus.
+type DailyStatus struct {
+	Date       openapi_types.Date  `json:"date"`
+	ImpactType *IncidentImpactType `json:"impactType,omitempty"`
+	Incidents  []Id                `json:"incidents"`
+}
+
+// DataQualityWarning defines model for DataQualityWarning.
+type DataQualityWarning struct {
+	// Field Name of the project field
+	Field   string `json:"field"`
+	Message string `json:"message"`
+	Value   string `json:"value"`
+}
+
+// Diagnostics defines model for Diagnostics.
+type Diagnostics struct {
+	CheckedAt time.Time              `json:"checkedAt"`
+	Ok        bool                   `json:"ok"`
+	Problems  []ConfigurationProblem `json:"problems"`
+	ProjectId *string                `json:"projectId,omitempty"`
+}
+
 // Id defines model for Id.
 type Id = string
 
+// ImpactType defines model for ImpactType.
+type ImpactType struct {
+	// AvailabilityWeight How much of the duration of incidents counts as downtime
+	AvailabilityWeight *float32 `json:"availabilityWeight,omitempty"`
+
+	// InProject Whether the impact type is an option of the impact type field of the project
+	InProject bool `json:"inProject"`
+
+	// Maintenance Whether the impact type marks maintenance windows instead of incidents
+	Maintenance bool               `json:"maintenance"`
+	Name        IncidentImpactType `json:"name"`
+
+	// Severity Position in the configured list of impact types, from 1 for the least severe one. 0 for the maintenance impact type.
+	Severity int `json:"severity"`
+
+	// StatuspageStatus Component status reported by the Statuspage.io compatible API
+	StatuspageStatus *string `json:"statuspageStatus,omitempty"`
+}
+
 // Incident defines model for Incident.
 type Incident struct {
 	Affects    []Id               `json:"affects"`
@@ -38,7 +137,13 @@ type Incident struct {
 	Id         string             `json:"id"`
 	ImpactType IncidentImpactType `json:"impactType"`
 	Phase      IncidentPhase      `json:"phase"`
-	Title      string             `json:"title"`
+
+	// PhaseChangedAt Time of the last phase change
+	PhaseChangedAt *time.Time `json:"phaseChangedAt,omitempty"`
+	Title          string     `json:"title"`
+
+	// Warnings Field values which could not be interpreted and were left out
+	Warnings *[]DataQualityWarning `json:"warnings,omitempty"`
 }
 
 // IncidentImpactType defines model for IncidentImpactType.
@@ -50,6 +155,149 @@ type IncidentPhase = string
 // Labels defines model for Labels.
 type Labels map[string]string
 
+// Maintenance defines model for Maintenance.
+type Maintenance struct {
+	Affects      []Id              `json:"affects"`
+	Id           string            `json:"id"`
+	Phase        IncidentPhase     `json:"phase"`
+	PlannedEnd   time.Time         `json:"plannedEnd"`
+	PlannedStart time.Time         `json:"plannedStart"`
+	Status       MaintenanceStatus `json:"status"`
+	Title        string            `json:"title"`
+}
+
+// MaintenanceStatus defines model for MaintenanceStatus.
+type MaintenanceStatus string
+
+// NewMaintenance defines model for NewMaintenance.
+type NewMaintenance struct {
+	Affects      []Id      `json:"affects"`
+	Description  *string   `json:"description,omitempty"`
+	PlannedEnd   time.Time `json:"plannedEnd"`
+	PlannedStart time.Time `json:"plannedStart"`
+	Title        string    `json:"title"`
+}
+
+// NewSubscriber defines model for NewSubscriber.
+type NewSubscriber struct {
+	// Components Only notify about incidents affecting any of these components, defaults to all
+	Components *[]Id  `json:"components,omitempty"`
+	Email      string `json:"email"`
+}
+
+// NewWebhookSubscription defines model for NewWebhookSubscription.
+type NewWebhookSubscription struct {
+	// Components Only deliver events of incidents affecting any of these components, defaults to all
+	Components *[]Id `json:"components,omitempty"`
+
+	// Events Event types to deliver, defaults to all
+	Events *[]WebhookEventType `json:"events,omitempty"`
+
+	// Secret Secret for signing payloads. The signature is sent as "X-SCS-Signature-256" header in the form "sha256=<hex encoded HMAC-SHA256 of the body>".
+	Secret string `json:"secret"`
+
+	// Url URL receiving events as HTTP POST requests
+	Url string `json:"url"`
+}
+
+// ProbeResult defines model for ProbeResult.
+type ProbeResult struct {
+	CheckedAt       time.Time `json:"checkedAt"`
+	DurationSeconds float64   `json:"durationSeconds"`
+	Error           *string   `json:"error,omitempty"`
+	Success         bool      `json:"success"`
+}
+
+// ProbeStatus defines model for ProbeStatus.
+type ProbeStatus struct {
+	ComponentId         Id     `json:"componentId"`
+	ConsecutiveFailures int    `json:"consecutiveFailures"`
+	IncidentId          *Id    `json:"incidentId,omitempty"`
+	Name                string `json:"name"`
+
+	// Results Most recent results, oldest first
+	Results []ProbeResult `json:"results"`
+	Target  string        `json:"target"`
+	Type    ProbeType     `json:"type"`
+}
+
+// ProbeType defines model for ProbeType.
+type ProbeType string
+
+// Problem Problem details as defined by RFC 7807
+type Problem struct {
+	Detail   *string `json:"detail,omitempty"`
+	Instance *string `json:"instance,omitempty"`
+	Status   int     `json:"status"`
+	Title    string  `json:"title"`
+
+	// Type URI identifying the problem type, "about:blank" if it is described by the status code alone
+	Type string `json:"type"`
+}
+
+// Subscriber defines model for Subscriber.
+type Subscriber struct {
+	Components []Id      `json:"components"`
+	Confirmed  bool      `json:"confirmed"`
+	CreatedAt  time.Time `json:"createdAt"`
+	Email      string    `json:"email"`
+	Id         Id        `json:"id"`
+}
+
+// SubscriberToken defines model for SubscriberToken.
+type SubscriberToken struct {
+	// Token Token of the link the subscriber received by email
+	Token *string `json:"token,omitempty"`
+}
+
+// WebhookDelivery defines model for WebhookDelivery.
+type WebhookDelivery struct {
+	Attempt     int              `json:"attempt"`
+	AttemptedAt time.Time        `json:"attemptedAt"`
+	Error       *string          `json:"error,omitempty"`
+	EventId     uint64           `json:"eventId"`
+	EventType   WebhookEventType `json:"eventType"`
+	Id          Id               `json:"id"`
+	StatusCode  *int             `json:"statusCode,omitempty"`
+	Success     bool             `json:"success"`
+}
+
+// WebhookEventType defines model for WebhookEventType.
+type WebhookEventType string
+
+// WebhookSubscription defines model for WebhookSubscription.
+type WebhookSubscription struct {
+	Components []Id               `json:"components"`
+	CreatedAt  time.Time          `json:"createdAt"`
+	Events     []WebhookEventType `json:"events"`
+	Id         Id                 `json:"id"`
+	Url        string             `json:"url"`
+}
+
+// GetAvailabilityParams defines parameters for GetAvailability.
+type GetAvailabilityParams struct {
+	// Start Start of time frame (RFC3339), defaults to 30 days before end
+	Start *time.Time `form:"start,omitempty" json:"start,omitempty"`
+
+	// End End of time frame (RFC3339), defaults to now
+	End *time.Time `form:"end,omitempty" json:"end,omitempty"`
+
+	// ExcludeMaintenance Whether to exclude maintenance windows from the time frame, defaults to server configuration
+	ExcludeMaintenance *bool `form:"excludeMaintenance,omitempty" json:"excludeMaintenance,omitempty"`
+}
+
+// GetHistoryParams defines parameters for GetHistory.
+type GetHistoryParams struct {
+	// Start First day of time frame, defaults to 89 days before end
+	Start *openapi_types.Date `form:"start,omitempty" json:"start,omitempty"`
+
+	// End Last day of time frame, defaults to today
+	End *openapi_types.Date `form:"end,omitempty" json:"end,omitempty"`
+
+	// Timezone IANA time zone determining day boundaries, defaults to server configuration
+	Timezone *string `form:"timezone,omitempty" json:"timezone,omitempty"`
+}
+
 // GetIncidentsParams defines parameters for GetIncidents.
 type GetIncidentsParams struct {
 	// Start Start of time frame to query for (RFC3339)
@@ -59,15 +307,60 @@ type GetIncidentsParams struct {
 	End time.Time `form:"end" json:"end"`
 }
 
+// ShowSubscriberConfirmationParams defines parameters for ShowSubscriberConfirmation.
+type ShowSubscriberConfirmationParams struct {
+	Token string `form:"token" json:"token"`
+}
+
+// ShowSubscriberUnsubscriptionParams defines parameters for ShowSubscriberUnsubscription.
+type ShowSubscriberUnsubscriptionParams struct {
+	Token string `form:"token" json:"token"`
+}
+
+// UnsubscribeSubscriberParams defines parameters for UnsubscribeSubscriber.
+type UnsubscribeSubscriberParams struct {
+	Token *string `form:"token,omitempty" json:"token,omitempty"`
+}
+
+// CreateMaintenanceJSONRequestBody defines body for CreateMaintenance for application/json ContentType.
+type CreateMaintenanceJSONRequestBody = NewMaintenance
+
+// CreateSubscriberJSONRequestBody defines body for CreateSubscriber for application/json ContentType.
+type CreateSubscriberJSONRequestBody = NewSubscriber
+
+// ConfirmSubscriberFormdataRequestBody defines body for ConfirmSubscriber for application/x-www-form-urlencoded ContentType.
+type ConfirmSubscriberFormdataRequestBody = SubscriberToken
+
+// UnsubscribeSubscriberFormdataRequestBody defines body for UnsubscribeSubscriber for application/x-www-form-urlencoded ContentType.
+type UnsubscribeSubscriberFormdataRequestBody = SubscriberToken
+
+// CreateWebhookJSONRequestBody defines body for CreateWebhook for application/json ContentType.
+type CreateWebhookJSONRequestBody = NewWebhookSubscription
+
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
+	// Get availability of all components within a time frame
+	// (GET /availability)
+	GetAvailability(ctx echo.Context, params GetAvailabilityParams) error
 
 	// (GET /components)
 	GetComponents(ctx echo.Context) error
 	// get specific component by id
 	// (GET /components/{componentId})
 	GetComponent(ctx echo.Context, componentId string) error
-
+	// Check in on behalf of a component
+	// (POST /components/{componentId}/heartbeat)
+	SendHeartbeat(ctx echo.Context, componentId string) error
+	// Get synthetic probes checking a component along with their recent results
+	// (GET /components/{componentId}/probes)
+	GetComponentProbes(ctx echo.Context, componentId string) error
+	// Re-run the checks of the project configuration
+	// (GET /diagnostics)
+	GetDiagnostics(ctx echo.Context) error
+	// Get per-day status history of all components
+	// (GET /history)
+	GetHistory(ctx echo.Context, params GetHistoryParams) error
+	// list the configured impact types from least to most severe
 	// (GET /impacttypes)
 	GetImpacttypes(ctx echo.Context) error
 	// Get specific incident by id
@@ -76,9 +369,51 @@ type ServerInterface interface {
 	// Get list of incidents
 	// (GET /incidents)
 	GetIncidents(ctx echo.Context, params GetIncidentsParams) error
+	// Get specific maintenance window by id
+	// (GET /maintenance/{maintenanceId})
+	GetMaintenance(ctx echo.Context, maintenanceId string) error
+	// Get list of maintenance windows
+	// (GET /maintenances)
+	GetMaintenances(ctx echo.Context) error
+	// Announce a maintenance window
+	// (POST /maintenances)
+	CreateMaintenance(ctx echo.Context) error
 
 	// (GET /phases)
 	GetPhases(ctx echo.Context) error
+	// Get list of email subscribers
+	// (GET /subscribers)
+	GetSubscribers(ctx echo.Context) error
+	// Subscribe to email notifications
+	// (POST /subscribers)
+	CreateSubscriber(ctx echo.Context) error
+	// Show a form confirming an email subscription
+	// (GET /subscribers/confirm)
+	ShowSubscriberConfirmation(ctx echo.Context, params ShowSubscriberConfirmationParams) error
+	// Confirm email subscription
+	// (POST /subscribers/confirm)
+	ConfirmSubscriber(ctx echo.Context) error
+	// Show a form cancelling an email subscription
+	// (GET /subscribers/unsubscribe)
+	ShowSubscriberUnsubscription(ctx echo.Context, params ShowSubscriberUnsubscriptionParams) error
+	// Cancel email subscription
+	// (POST /subscribers/unsubscribe)
+	UnsubscribeSubscriber(ctx echo.Context, params UnsubscribeSubscriberParams) error
+	// Get list of webhook subscriptions
+	// (GET /webhooks)
+	GetWebhooks(ctx echo.Context) error
+	// Subscribe a webhook to incident events
+	// (POST /webhooks)
+	CreateWebhook(ctx echo.Context) error
+	// Delete webhook subscription
+	// (DELETE /webhooks/{webhookId})
+	DeleteWebhook(ctx echo.Context, webhookId string) error
+	// Get specific webhook subscription by id
+	// (GET /webhooks/{webhookId})
+	GetWebhook(ctx echo.Context, webhookId string) error
+	// Get recent delivery attempts of a webhook subscription
+	// (GET /webhooks/{webhookId}/deliveries)
+	GetWebhookDeliveries(ctx echo.Context, webhookId string) error
 }
 
 // ServerInterfaceWrapper converts echo contexts to parameters.
@@ -86,6 +421,38 @@ type ServerInterfaceWrapper struct {
 	Handler ServerInterface
 }
 
+// GetAvailability converts echo context to params.
+func (w *ServerInterfaceWrapper) GetAvailability(ctx echo.Context) error {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetAvailabilityParams
+	// ------------- Optional query parameter "start" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "start", ctx.QueryParams(), &params.Start)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter start: %s", err))
+	}
+
+	// ------------- Optional query parameter "end" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "end", ctx.QueryParams(), &params.End)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter end: %s", err))
+	}
+
+	// ------------- Optional query parameter "excludeMaintenance" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "excludeMaintenance", ctx.QueryParams(), &params.ExcludeMaintenance)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter excludeMaintenance: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetAvailability(ctx, params)
+	return err
+}
+
 // GetComponents converts echo context to params.
 func (w *ServerInterfaceWrapper) GetComponents(ctx echo.Context) error {
 	var err error
@@ -111,6 +478,83 @@ func (w *ServerInterfaceWrapper) GetComponent(ctx echo.Context) error {
 	return err
 }
 
+// SendHeartbeat converts echo context to params.
+func (w *ServerInterfaceWrapper) SendHeartbeat(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "componentId" -------------
+	var componentId string
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "componentId", runtime.ParamLocationPath, ctx.Param("componentId"), &componentId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter componentId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{""})
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.SendHeartbeat(ctx, componentId)
+	return err
+}
+
+// GetComponentProbes converts echo context to params.
+func (w *ServerInterfaceWrapper) GetComponentProbes(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "componentId" -------------
+	var componentId string
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "componentId", runtime.ParamLocationPath, ctx.Param("componentId"), &componentId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter componentId: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetComponentProbes(ctx, componentId)
+	return err
+}
+
+// GetDiagnostics converts echo context to params.
+func (w *ServerInterfaceWrapper) GetDiagnostics(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{""})
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetDiagnostics(ctx)
+	return err
+}
+
+// GetHistory converts echo context to params.
+func (w *ServerInterfaceWrapper) GetHistory(ctx echo.Context) error {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetHistoryParams
+	// ------------- Optional query parameter "start" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "start", ctx.QueryParams(), &params.Start)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter start: %s", err))
+	}
+
+	// ------------- Optional query parameter "end" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "end", ctx.QueryParams(), &params.End)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter end: %s", err))
+	}
+
+	// ------------- Optional query parameter "timezone" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "timezone", ctx.QueryParams(), &params.Timezone)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter timezone: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetHistory(ctx, params)
+	return err
+}
+
 // GetImpacttypes converts echo context to params.
 func (w *ServerInterfaceWrapper) GetImpacttypes(ctx echo.Context) error {
 	var err error
@@ -161,6 +605,42 @@ func (w *ServerInterfaceWrapper) GetIncidents(ctx echo.Context) error {
 	return err
 }
 
+// GetMaintenance converts echo context to params.
+func (w *ServerInterfaceWrapper) GetMaintenance(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "maintenanceId" -------------
+	var maintenanceId string
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "maintenanceId", runtime.ParamLocationPath, ctx.Param("maintenanceId"), &maintenanceId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter maintenanceId: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetMaintenance(ctx, maintenanceId)
+	return err
+}
+
+// GetMaintenances converts echo context to params.
+func (w *ServerInterfaceWrapper) GetMaintenances(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetMaintenances(ctx)
+	return err
+}
+
+// CreateMaintenance converts echo context to params.
+func (w *ServerInterfaceWrapper) CreateMaintenance(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{""})
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.CreateMaintenance(ctx)
+	return err
+}
+
 // GetPhases converts echo context to params.
 func (w *ServerInterfaceWrapper) GetPhases(ctx echo.Context) error {
 	var err error
@@ -170,6 +650,165 @@ func (w *ServerInterfaceWrapper) GetPhases(ctx echo.Context) error {
 	return err
 }
 
+// GetSubscribers converts echo context to params.
+func (w *ServerInterfaceWrapper) GetSubscribers(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{""})
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetSubscribers(ctx)
+	return err
+}
+
+// CreateSubscriber converts echo context to params.
+func (w *ServerInterfaceWrapper) CreateSubscriber(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.CreateSubscriber(ctx)
+	return err
+}
+
+// ShowSubscriberConfirmation converts echo context to params.
+func (w *ServerInterfaceWrapper) ShowSubscriberConfirmation(ctx echo.Context) error {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ShowSubscriberConfirmationParams
+	// ------------- Required query parameter "token" -------------
+
+	err = runtime.BindQueryParameter("form", true, true, "token", ctx.QueryParams(), &params.Token)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter token: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.ShowSubscriberConfirmation(ctx, params)
+	return err
+}
+
+// ConfirmSubscriber converts echo context to params.
+func (w *ServerInterfaceWrapper) ConfirmSubscriber(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.ConfirmSubscriber(ctx)
+	return err
+}
+
+// ShowSubscriberUnsubscription converts echo context to params.
+func (w *ServerInterfaceWrapper) ShowSubscriberUnsubscription(ctx echo.Context) error {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ShowSubscriberUnsubscriptionParams
+	// ------------- Required query parameter "token" -------------
+
+	err = runtime.BindQueryParameter("form", true, true, "token", ctx.QueryParams(), &params.Token)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter token: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.ShowSubscriberUnsubscription(ctx, params)
+	return err
+}
+
+// UnsubscribeSubscriber converts echo context to params.
+func (w *ServerInterfaceWrapper) UnsubscribeSubscriber(ctx echo.Context) error {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params UnsubscribeSubscriberParams
+	// ------------- Optional query parameter "token" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "token", ctx.QueryParams(), &params.Token)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter token: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.UnsubscribeSubscriber(ctx, params)
+	return err
+}
+
+// GetWebhooks converts echo context to params.
+func (w *ServerInterfaceWrapper) GetWebhooks(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{""})
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetWebhooks(ctx)
+	return err
+}
+
+// CreateWebhook converts echo context to params.
+func (w *ServerInterfaceWrapper) CreateWebhook(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{""})
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.CreateWebhook(ctx)
+	return err
+}
+
+// DeleteWebhook converts echo context to params.
+func (w *ServerInterfaceWrapper) DeleteWebhook(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "webhookId" -------------
+	var webhookId string
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "webhookId", runtime.ParamLocationPath, ctx.Param("webhookId"), &webhookId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter webhookId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{""})
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.DeleteWebhook(ctx, webhookId)
+	return err
+}
+
+// GetWebhook converts echo context to params.
+func (w *ServerInterfaceWrapper) GetWebhook(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "webhookId" -------------
+	var webhookId string
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "webhookId", runtime.ParamLocationPath, ctx.Param("webhookId"), &webhookId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter webhookId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{""})
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetWebhook(ctx, webhookId)
+	return err
+}
+
+// GetWebhookDeliveries converts echo context to params.
+func (w *ServerInterfaceWrapper) GetWebhookDeliveries(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "webhookId" -------------
+	var webhookId string
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "webhookId", runtime.ParamLocationPath, ctx.Param("webhookId"), &webhookId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter webhookId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{""})
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetWebhookDeliveries(ctx, webhookId)
+	return err
+}
+
 // This is a simple interface which specifies echo.Route addition functions which
 // are present on both echo.Echo and echo.Group, since we want to allow using
 // either of them for path registration
@@ -198,30 +837,102 @@ func RegisterHandlersWithBaseURL(router EchoRouter, si ServerInterface, baseURL
 		Handler: si,
 	}
 
+	router.GET(baseURL+"/availability", wrapper.GetAvailability)
 	router.GET(baseURL+"/components", wrapper.GetComponents)
 	router.GET(baseURL+"/components/:componentId", wrapper.GetComponent)
+	router.POST(baseURL+"/components/:componentId/heartbeat", wrapper.SendHeartbeat)
+	router.GET(baseURL+"/components/:componentId/probes", wrapper.GetComponentProbes)
+	router.GET(baseURL+"/diagnostics", wrapper.GetDiagnostics)
+	router.GET(baseURL+"/history", wrapper.GetHistory)
 	router.GET(baseURL+"/impacttypes", wrapper.GetImpacttypes)
 	router.GET(baseURL+"/incident/:incidentId", wrapper.GetIncident)
 	router.GET(baseURL+"/incidents", wrapper.GetIncidents)
+	router.GET(baseURL+"/maintenance/:maintenanceId", wrapper.GetMaintenance)
+	router.GET(baseURL+"/maintenances", wrapper.GetMaintenances)
+	router.POST(baseURL+"/maintenances", wrapper.CreateMaintenance)
 	router.GET(baseURL+"/phases", wrapper.GetPhases)
+	router.GET(baseURL+"/subscribers", wrapper.GetSubscribers)
+	router.POST(baseURL+"/subscribers", wrapper.CreateSubscriber)
+	router.GET(baseURL+"/subscribers/confirm", wrapper.ShowSubscriberConfirmation)
+	router.POST(baseURL+"/subscribers/confirm", wrapper.ConfirmSubscriber)
+	router.GET(baseURL+"/subscribers/unsubscribe", wrapper.ShowSubscriberUnsubscription)
+	router.POST(baseURL+"/subscribers/unsubscribe", wrapper.UnsubscribeSubscriber)
+	router.GET(baseURL+"/webhooks", wrapper.GetWebhooks)
+	router.POST(baseURL+"/webhooks", wrapper.CreateWebhook)
+	router.DELETE(baseURL+"/webhooks/:webhookId", wrapper.DeleteWebhook)
+	router.GET(baseURL+"/webhooks/:webhookId", wrapper.GetWebhook)
+	router.GET(baseURL+"/webhooks/:webhookId/deliveries", wrapper.GetWebhookDeliveries)
 
 }
 
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/8xWXYvbOhD9K2LufbgXvLGT9KV+24Z2CV3a0OxTl31QrHFWiy1pJblggv97GTmx89Ws",
-	"IbAUFtaWZkbnHJ8ZZQOZLo1WqLyDdAMue8aSh8fZboNejNUGrZcYtnieY+ZRfKrpTXosw/K/FnNI4Z+4",
-	"rxlvC8ZzAU0EvjYIKXBreU3vQjpT8PobL5EKbLedt1KtaV+Ks8sFX2Hx5pH3bVTTRGDxtZIWBaSPVPPw",
-	"4K5etE/sqUOrVy+YeTp3fh7OXGVSXBDKXanSCtdc3YbyubYl95CC4B5vvAzwTwChEiguJKiqKPiqQEi9",
-	"rTAarLssDc/8Q1h+g8hWknmf0URgnrkbnLoIwSSH9MU5e5z7rm1s1Al/gHkH4OynPQV86VMvdkxOIu47",
-	"b3IhpJda8WJxYIqTlCMwREyqXIfYljssZ0u29NxXji34GtntYg4R/ELrpFaQwniUUCVtUHEjIYXpKBlN",
-	"iDH3z+HU+LDR1xjsQbg4gSRrwx36WR9F8jqjlWthT5KE/mVa+a3XuTGFzEJ6/OIIx26ADDZ8P2ROfE8y",
-	"CHSZlca3JL9/pdUm2ucSb7rnuWgGMQuqWF6iR+sgfdyApOqkFPVGmEWwVxX2fdY2TM/y2JNPV6o2UKw/",
-	"iBOBq8qS2xpS0oE5g5nMZca6amxVMylCaNy2BjG4aIn5Xth7eOL87BhuDrnNjze7pzec0c3vIcboa/41",
-	"vujwD7HF3b4tdmQOXLFdc0Mkc6eaHZ6/9Nx6pnNGVw/LKZJ5zV4rtDXLtWX//fgym06nH/+nWU0ZYavX",
-	"21GBi1ILzHlV0B03SSaTm2R8k4wfxkka/kbJOPkJ0aCLs4mO0X9W4hrsqMRw5B+uQf70no05uB2PfVdI",
-	"F7zQWyw4LtzKF+22aCPek2T/82PY4Gma3wEAAP//WdzRg0wLAAA=",
+	"H4sIAAAAAAAC/+xcbW/cOJL+K4TuPtziZLvtJLMzBu6Dx5kkxk0yvtiDHG4cHNhidYtrNaklqe70Bv7v",
+	"hyIpiWpRbrUdd3KZBRY7bokvxaqnXlnK5ySTi1IKEEYnp58TBbqUQoP98TNlr6mBFV3jr0wKA8Lgn7Qs",
+	"C55Rw6U4KpWcFrD4979pKfCdznJYUPzrXxXMktPkX47aLY7cW3106WYld3d3acJAZ4qXuFxymrwCk+Vc",
+	"zAmjhpKZkgvymps31ZTMKC+AJXdp4sm65guQldkndZ4UxhkR0hDHL0a4IIYvAGl7J80rWQm2T6qucyAK",
+	"/l6BNsCQKFmpDAiToC2Z8Ilrg8TVa+yRtjOxJtLkoAgoJVVKuMiKiqGEuVjSgjNSUkUXYEDpBOf7RXHP",
+	"83or/FEqWYIy3KGTzmaQGWA/W3RyAwu9jcoLCx6zLiE5TahSdI2/GddlQdfv6AJwAf9aG8XFHN9zFn1c",
+	"0CkUW7f81Y3CY6GAuAKWnP6Ba3Y3btZLw4N9bKiV079BZkXYsORsSXlBp7zgZh1hz8bbrlAuQWUgDJ0D",
+	"kTNicrDwJTOUg/3ZHIWsqCZ+sQLpnEm1oCY5TZis3BNPoqgWU1BIYjP5go0TCZMrgQRcQSYF0316X/oB",
+	"ZMVNzsUGxSlZAZ/niP3pmvBFSTNDLFWjyAWnrO1IauAAV0/SvtjhE6IX2CCpaJRIPcrZrw3+sgqIkWRB",
+	"uTAgqMjwWILJlR5HrzZUmbEUbwAvFE29kuNA2oVMXyj9s98LzzdcG6kiyNwdHXStR+v4S8qL9ZWhptJ9",
+	"Zb+XGXab+JHEjM8rZQ1kYEE3jpVDdtuHwwUDYfiMg6o1zbkxYsenBA7nh+SmJUXfJEQqcpMUVJuDMqca",
+	"bpIYEHMuTH+7N3KF2JrxT3Yvb81j8xegNZ3HTN4ml+zB2gkxFoVc73EG0dmDa4wkp7nX9vEWYIiMI2Mv",
+	"2hk43z/Vj/IIG8f3xLZrx89v6H9VFDXnA1UCz9Njw4xDwfoSQ/NfQ6NUElckbuhOQkuTJS2qEeKs13bD",
+	"t4iV07mQ2vBMDwAe2JkZbzzlbUDfVMoCqMDnHqbj5RZVyYhv9wy9YNv5IhHj7ZkComKcuYgHBRcdBA97",
+	"5A/WX8XVd1FleQ0I5o+IvxsEkkxW+B+qSW2jQ8cxKyQ1Mb/BkVP2AL19P+RgQzTcM/CfhGtCBZFlTcPm",
+	"ewumDfQmaUTCgbMbv/uCqlsd85OEC22Asg5bovsKH9Xtbkw0LEHFgyepOf5JfCCSeTACIwXXxhLVnkKn",
+	"Lgg4JjPpjlgA1YbY9YFIAYdk0rwLDxsscngj2uPhiHkTCphKl3QOrfHtEtv4Y+LGEgWlVD5Uwh2vmiUO",
+	"ubRxHzV8WgA5u7zYGk4IF7s2vOoKOoRcVIk844eCe/3IyH4Kcyp2sU8g2L0GTVSFC4NPjaogHZ0rPNax",
+	"2TBg7NRLO7iedZ5TMa8PFYlUve5itEHsBJLZGUkaZ0HvbIabIu6QVs4TRkD5ypoN64E0WeU8y9GmFS6n",
+	"ngJBCKlSAcKUCkZWqCkFzAyRlUnScZiI+ORtvt6mZe5AaYPBjvhqWdwH564T6PuIjpxiI35tUkvKmDU1",
+	"tLjs6EdfCpvEvO0a3KdQrwGwPxCsBRUC2C+75GJ+ztUu+VBtM7cRGPAvyCYGsD4ORh1yOyduiLoPXn2K",
+	"Tj8nIKoFbolks6oAZo3uQankXIHGBfFsBWpSsGjLjHewegKkLLi4cKOPI/WW0BR8HpTqHpAwUpy7SDIm",
+	"uHewuqqmeGgMxIbz4Yih/E0UazSKfLYmdCorE0SBjhyso1Gx9nZcB5UbnRIGM1oVRmNKSIsiSXcR4qbc",
+	"YEF5sZ1bbtgAHz7ANJfy1rOjwcCODGFQ8CUoAkvLCDn7mlxZxgn9BZ+7ABD38SQ/eHPPOLtqHRVskqIh",
+	"UxDx81f2uQ0xNZ+jIyQlXReSMn1IsHSMT6mplI33NdJNNblJ/vvg6vzq4Kp+eXDy4oebhORAGag69kVF",
+	"IzeJzunJix/+46aaTJ5lOXwiIDKJ9a83b8/OD67enJ28+KEONaaSre1AuEk6cW2rmJUq+sf4/f2vREEG",
+	"fIkn8LKnmry5vr4kl79dXdclcL01bMX1G37FoIopJbwHXRXmiyS+dR4XVA3HFCaxYB61kbrKMtA6lk3H",
+	"qjc+o61n9ekZ5MFQTWfnIl4mhYasMnwJrygvKtUJZIKUplblsQuLobq9suKLqOZbqY1FkjDED0qJLBho",
+	"rLwoPTrCDFES0UdD1dzp40C4NmJ1p+vxrKtbvvT1br9pnOEtUwYFXoetdWCRG1Pishn+PxM6GkUERdGN",
+	"VNm9IAwM5YUrWcCMC5d+vn91Tv764+SvSbqBLjc8nkoJbepQ5Z7Qro+r4TylFsamubkg3BVv12hwgnKq",
+	"NespuUmsSz6dFlTc3iSEzwg3aELdQtM2x/apN5pEQgspttfpa2n60MMfLCa18aHFI7ycLXGoBbB4/S5T",
+	"QM1uFnEomqjTim00xkJut2YaHjskPaTzfk5ey1uIBCamfryRR+PjJpHm4tb+oZvVvNdycKhp7Iu/R4/3",
+	"+S9d6BC74zMGFqWJ492/3FEqgx4Hlo1NbpaquDA/PI8WpqAJVB4Q2PCRpt9pxblkEGfBeC/p4LOsbWlL",
+	"fcPHpMvRdvGPw5L7JWRCbVBr/3bowRjcLRza5O9/XQGm80KBlsVyIId7QFD9GFPwAF1f7rTvmFh3LER8",
+	"JDkiX3cxISwbsxHakHvshgu8Kyx/XuHWjt1ToArUWWXyponC4s8+bnlkvattmuBiJi2lzk0lV+dXvjRL",
+	"Lum8rsYuQWlndI4PJ3g+WYKgJU9Ok2eHk8OTJE1KanJLwtFmC4APRhAVNvhDbU5eQ7eRABdoujFO/+jl",
+	"EpjyWlvX3mf/2/tX58+ePfvpL93s5tmE4JUqmcJMKiDujpnjIn+v0JzVoVtzC922moy72O6lXIKNo0zI",
+	"1QApjsjHEtLcZ8i6ESB6iRHpDejSqUFhmpuFl11DhLt93naq7+05eubvY9rt9TqZTO5pCuo3A428pYu1",
+	"qvSLsL2eod/+E0e9mJwM7dDQfhQ0qdkpz7dP2eges7tbpm+f2vY5oQNYLKhaOx0iobIhCmlRBEWHum+F",
+	"BrK2ixx17fKQip6H1miPgvtOhLXB6aPPQep0N4rvfbtotRCNbauE3YSs9TDusqinjY0BeawyjhTlkOie",
+	"j5FD09b4/0kx52CILiHjM5612mibxFhyLyiOcqDKTIHavUupo3U1teQZ2JIZI80ETajxd7wSzX0ZmHBg",
+	"7m5rSYuU0MrkIAzKNUgVXbHOhDlFszTmzy6EcAMOycWM0OA910QuQbEKUry4r+NH+7wETLul2Ojvw/u1",
+	"Orx0BAcnUaCrhb997irHFQj2ph64Z+14HmmcaFigIJOKuT7h55PjSGIvboVcCc9id/HOdcuRh+POh4GW",
+	"AWEA+MfHu48hLM+xJocVVCnIFHJazKzHCEm4F5tYhoBx/uLSDf2Wrdf4UttgW9+AYfsCbl2vhcnB8Iw4",
+	"pru2PXu9EOpQIcXc+nhULq42qotOnKzbTDWPlerf2+YMjdVttSYruu60hTFYcmrAR46rnBpvMmyYCJ9K",
+	"yIw+JFjuQwqNJDZKrPvnedD8QTWhTTnLm5mbZM5NXk1vEnfKmNq/BhM2hT2h4wq3+cISHqun7+FAVd5e",
+	"IkP0Zp9eNzK3Us7btteohF9J5aXbNcHuGaProRZnBaZSQtvnC9k2EIUNU7gQBp7tdZiRlfuawiBaGF0f",
+	"kpeYlOEm3evEnC6BCNnvOuoBoO7s3ZIqvuJK2y27SVk3xfnxpy+TJI5Jy36l2+kxktH1Q5LDMQRcnL07",
+	"czv/QwogDDm34MJ97LImU4yvqOKgH5wG4uL/cPXlr2ywe33g32faV4I6QNn5Cr/X/n4C6IyD0y1kw7AL",
+	"uB7uAkzxigEEdqCxFM15we0HPwXVZkBXL4Id9yH2bt/adyZwZPdmx2cgHu+XXehvZGilvfi9sT363F5y",
+	"3pt+Ng2SY+K3ds1vJvls6P8T5p6vw9yzScSC1LPztcI2BOiHFGabABCTnKYWusXBDiOnYUhyMjk5OZgc",
+	"H0yOr48np/Z/h5Pjyf+MbBodU7rdiXbnmUdS/vwxlO/Fd7Zq8136zKZJvsG21YfA6R19Dn5ssZHdkvd2",
+	"M9lZ+ZuxlOEp/uzGsn9XEprN4K0eiYv9hD4dEX7Xihv7cvUubaqkXVGc27vTrpb6Rr2fJVt/MQ3a6GS+",
+	"u7vb1O27HgqO96W/5/6u/8lLFmdCyArlQiNScgpkmwzuVZ1LN2Kfrq75DuB7uW1q23/uZfVVMGwf/G73",
+	"e+oy6ljEhobFNkeRkHWBWdm8fBHMfpzo5tjaK3XdV+6Dopza4skUyJJrjmmykcQ3g4UNWnbBQ/Ih5wUQ",
+	"Wo+g7gM/TUoQ+G9VpERIMquU+4cs7JZ1r7SRdjnKmAKtU3+h4jqRCVVAFDVACr6wRNjboIJjUw+phVGX",
+	"k32jWr0UoXNqLwmKtf8oSxNudHitbO9rmomxGoCzPIHYn8z6htAaY3xP+kI9yzIoDRY3OmLwMrAh0MlP",
+	"O+Dw0V6vORNK2Yndfo3hmeQj1wCwR57wwfLOeXgwhKvGggFeHypw3fil/Vc5UOhU3+oAtSnR0iFcZ1QI",
+	"UJqUCmb1v5fTdB8yiUQOYz12m5fLQH4hjQMx9Wbd0XZGftlg2sAnc5SbRdFF4eZCT3P3gwwh1H3e4Blp",
+	"tVR0bZTbczj2cTMfoH2fDlar1QFuf1Cpwn9NMV4dN1tZRylkVABlQbn4GhLwvIvye1PpKtH8GlS839sx",
+	"Y/UuWHZX3aMig+IBqve7CMf/U/k8J4tRytcvpLs7fq6JofhH03GHS6dEqvaJKzZR79OnayIFHGQFz24D",
+	"GCAVLv8pvBPXtjZFfpy8+PEvMekGqOtYgR3EukWMX8eafPPWwyngkPFYuRbje0PzD/WYfcTlsR7ubzBA",
+	"93zrcHRr7u/P9nSRZ5R5+83/B0n4SnWANnCljdCMbO8hfJ97RxmOPvu/fMWVQQEG+lJ9aZ+HUt3WneVm",
+	"7OHYbqMoTHHzLar+lO0sIwGyL4Vuiq0xVtXl1jHl9AYyuwUgQ7g78h9Kcxhjm1+2g5+Q1j3a/+Zbr2/I",
+	"9vteNi+YNfGfQWnXsxhXtbu7u/8bAGQovfhbVgAA",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
@@ -0,0 +1,225 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/joshmue/scs-status-page-openapi/pkg/api"
+	"github.com/labstack/echo/v4"
+)
+
+// AlertmanagerConfig configures the receiver of Alertmanager webhooks.
+type AlertmanagerConfig struct {
+	// Rules map alerts to incidents. If empty, the receiver is disabled.
+	Rules []AlertRule
+	// StateFile persists which alert opened which incident across restarts, if set.
+	StateFile string
+}
+
+// AlertRule maps alerts with matching labels to incidents. The first matching rule applies.
+type AlertRule struct {
+	// Matchers are labels an alert needs to have with exactly these values
+	Matchers   map[string]string `json:"matchers"`
+	Components []api.Id          `json:"components"`
+	ImpactType string            `json:"impactType"`
+}
+
+func (r *AlertRule) matches(labels map[string]string) bool {
+	for name, value := range r.Matchers {
+		if labels[name] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// LoadAlertRules reads a JSON list of alert rules from a file.
+func LoadAlertRules(path string) ([]AlertRule, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rules := []AlertRule{}
+	if err := json.Unmarshal(content, &rules); err != nil {
+		return nil, err
+	}
+	for i, rule := range rules {
+		if len(rule.Components) == 0 || rule.ImpactType == "" {
+			return nil, fmt.Errorf("alert rule %d: expected components and impact type", i)
+		}
+	}
+	return rules, nil
+}
+
+// alertmanagerPayload is the body of webhooks sent by Alertmanager.
+type alertmanagerPayload struct {
+	Version string  `json:"version"`
+	Status  string  `json:"status"`
+	Alerts  []alert `json:"alerts"`
+}
+
+type alert struct {
+	Status      string            `json:"status"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	StartsAt    time.Time         `json:"startsAt"`
+	EndsAt      time.Time         `json:"endsAt"`
+	Fingerprint string            `json:"fingerprint"`
+}
+
+// title returns the summary of an alert, falling back to its name.
+func (a *alert) title() string {
+	if summary := a.Annotations["summary"]; summary != "" {
+		return summary
+	}
+	return a.Labels["alertname"]
+}
+
+// alertStore maps fingerprints of firing alerts to the IDs of the incidents they opened.
+type alertStore struct {
+	mutex     sync.Mutex
+	incidents map[string]string
+}
+
+// LoadAlertIncidents loads which alert opened which incident.
+func (s *ServerImplementation) LoadAlertIncidents() error {
+	s.alertStore.mutex.Lock()
+	defer s.alertStore.mutex.Unlock()
+	s.alertStore.incidents = map[string]string{}
+	return loadState(s.Alertmanager.StateFile, &s.alertStore.incidents)
+}
+
+// saveAlertIncidents persists which alert opened which incident. The caller must hold the mutex.
+func (s *ServerImplementation) saveAlertIncidents() error {
+	return saveState(s.Alertmanager.StateFile, s.alertStore.incidents)
+}
+
+// RegisterAlertmanagerHandlers adds a receiver for Alertmanager webhooks.
+func (s *ServerImplementation) RegisterAlertmanagerHandlers(router api.EchoRouter) {
+	router.POST("/alertmanager", s.ReceiveAlerts)
+}
+
+// ReceiveAlerts opens an incident for every firing alert matching a rule, updates the
+// incident of alerts that keep firing and moves it to the last phase once the alert resolves.
+// Failures respond with 500, so Alertmanager retries the whole payload.
+func (s *ServerImplementation) ReceiveAlerts(ctx echo.Context) error {
+	if err := s.authorize(ctx); err != nil {
+		return err
+	}
+	if len(s.Alertmanager.Rules) == 0 {
+		return echo.NewHTTPError(http.StatusNotImplemented, "the Alertmanager receiver is disabled")
+	}
+	var payload alertmanagerPayload
+	if err := ctx.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	s.alertStore.mutex.Lock()
+	defer s.alertStore.mutex.Unlock()
+	failed := false
+	for _, alert := range payload.Alerts {
+		if alert.Fingerprint == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, "expected alerts to have a fingerprint")
+		}
+		var err error
+		if alert.Status == "resolved" {
+			err = s.resolveAlert(ctx.Request().Context(), alert)
+		} else {
+			err = s.fireAlert(ctx.Request().Context(), ctx.Logger(), alert)
+		}
+		if err != nil {
+			ctx.Logger().Errorf(`Handling alert "%s" failed: %s`, alert.Fingerprint, err)
+			failed = true
+		}
+	}
+	if err := s.saveAlertIncidents(); err != nil {
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(500)
+	}
+	if failed {
+		return echo.NewHTTPError(500)
+	}
+	return ctx.NoContent(200)
+}
+
+// fireAlert opens or updates the incident of a firing alert. The caller must hold the mutex.
+func (s *ServerImplementation) fireAlert(ctx context.Context, logger echo.Logger, alert alert) error {
+	var rule *AlertRule
+	for i := range s.Alertmanager.Rules {
+		if s.Alertmanager.Rules[i].matches(alert.Labels) {
+			rule = &s.Alertmanager.Rules[i]
+			break
+		}
+	}
+	if rule == nil {
+		logger.Debugf(`No rule matches alert "%s"`, alert.Fingerprint)
+		return nil
+	}
+
+	if incidentId, ok := s.alertStore.incidents[alert.Fingerprint]; ok {
+		incident, err := s.Incident(ctx, logger, incidentId)
+		switch {
+		case errors.Is(err, ErrNotFound):
+			// The incident was deleted, so a new one is opened
+			delete(s.alertStore.incidents, alert.Fingerprint)
+		case err != nil:
+			return err
+		case incident.Phase == s.LastPhase:
+			// Incidents closed by hand while the alert keeps firing are not reopened
+			return nil
+		case incident.ImpactType != rule.ImpactType:
+			return s.setItemFieldValue(ctx, incidentId, s.Fields.ImpactType, rule.ImpactType)
+		default:
+			return nil
+		}
+	}
+
+	phases, err := s.Phases(ctx)
+	if err != nil {
+		return err
+	}
+	if len(phases) == 0 {
+		return fmt.Errorf("could not determine initial phase")
+	}
+	beganAt := alert.StartsAt
+	itemId, err := s.createItem(ctx, newItem{
+		Title:      alert.title(),
+		Body:       alert.Annotations["description"],
+		Affects:    rule.Components,
+		Phase:      phases[0],
+		ImpactType: rule.ImpactType,
+		BeganAt:    &beganAt,
+	})
+	// Remember partially created items, so retries do not create duplicates
+	if itemId != "" {
+		s.alertStore.incidents[alert.Fingerprint] = itemId
+	}
+	return err
+}
+
+// resolveAlert moves the incident of a resolved alert to the last phase. Incidents deleted
+// in the meantime count as resolved. The caller must hold the mutex.
+func (s *ServerImplementation) resolveAlert(ctx context.Context, alert alert) error {
+	incidentId, ok := s.alertStore.incidents[alert.Fingerprint]
+	if !ok {
+		return nil
+	}
+	var err error
+	if !alert.EndsAt.IsZero() {
+		err = s.setItemTimestamp(ctx, incidentId, s.Fields.EndedAt, s.Fields.EndedAtTime, alert.EndsAt, true)
+	}
+	if err == nil {
+		err = s.setItemFieldValue(ctx, incidentId, s.Fields.Phase, s.LastPhase)
+	}
+	if err != nil && !isNotFound(err) {
+		return err
+	}
+	delete(s.alertStore.incidents, alert.Fingerprint)
+	return nil
+}
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestResolveAlertOfDeletedIncident(t *testing.T) {
+	s := &ServerImplementation{
+		GithubV4Client: fakeGithub(t, func(query string, variables map[string]interface{}) string {
+			if strings.Contains(query, "updateProjectV2ItemFieldValue") {
+				return `{"errors":[{"message":"Could not resolve to a node with the global id of 'item'"}]}`
+			}
+			return `{"data":{"node":{"field":{"id":"field","name":"Status","dataType":"SINGLE_SELECT","options":[{"id":"option","name":"Resolved"}]}}}}`
+		}),
+		ProjectID: "project",
+		Fields:    DefaultFieldNames,
+		LastPhase: "Resolved",
+	}
+	s.alertStore.incidents = map[string]string{"fingerprint": "item"}
+	if err := s.resolveAlert(context.Background(), alert{Status: "resolved", Fingerprint: "fingerprint"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.alertStore.incidents["fingerprint"]; ok {
+		t.Errorf("expected the alert to be forgotten; got %v", s.alertStore.incidents)
+	}
+}
@@ -0,0 +1,149 @@
+package server
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/joshmue/scs-status-page-openapi/pkg/api"
+	"github.com/labstack/echo/v4"
+)
+
+// AvailabilityConfig configures how availability is calculated from incidents.
+type AvailabilityConfig struct {
+	// ImpactTypeWeights sets how much of an incident's duration counts as downtime,
+	// e.g. 0.5 for an impact type which only degrades a component.
+	// Unmapped impact types count fully.
+	ImpactTypeWeights map[string]float64
+	// ExcludeMaintenance removes maintenance windows from the time frame by default.
+	ExcludeMaintenance bool
+}
+
+// weightedInterval is a period of time in which a component was impacted.
+type weightedInterval struct {
+	Start  time.Time
+	End    time.Time
+	Weight float64
+	// Excluded intervals are removed from the time frame instead of counting as downtime
+	Excluded bool
+}
+
+// calculateAvailability returns the weighted downtime and the excluded time within
+// [start, end). Overlapping intervals are merged: At any point in time, only the
+// highest weight counts, while any excluded interval takes precedence.
+func calculateAvailability(intervals []weightedInterval, start time.Time, end time.Time) (downtime time.Duration, excluded time.Duration) {
+	boundaries := []time.Time{start, end}
+	for _, interval := range intervals {
+		if interval.Start.After(start) && interval.Start.Before(end) {
+			boundaries = append(boundaries, interval.Start)
+		}
+		if interval.End.After(start) && interval.End.Before(end) {
+			boundaries = append(boundaries, interval.End)
+		}
+	}
+	sort.Slice(boundaries, func(i, j int) bool {
+		return boundaries[i].Before(boundaries[j])
+	})
+	var weightedDowntime float64
+	for i := 0; i+1 < len(boundaries); i++ {
+		segmentStart, segmentEnd := boundaries[i], boundaries[i+1]
+		if !segmentStart.Before(segmentEnd) {
+			continue
+		}
+		weight := 0.0
+		isExcluded := false
+		for _, interval := range intervals {
+			if interval.Start.After(segmentStart) || !interval.End.After(segmentStart) {
+				continue
+			}
+			if interval.Excluded {
+				isExcluded = true
+			} else if interval.Weight > weight {
+				weight = interval.Weight
+			}
+		}
+		length := segmentEnd.Sub(segmentStart)
+		if isExcluded {
+			excluded += length
+		} else {
+			weightedDowntime += weight * float64(length)
+		}
+	}
+	return time.Duration(weightedDowntime), excluded
+}
+
+// impactTypeWeight returns how much an incident of the given impact type counts as downtime.
+func (s *ServerImplementation) impactTypeWeight(impactType api.IncidentImpactType) float64 {
+	if weight, ok := s.Availability.ImpactTypeWeights[impactType]; ok {
+		return weight
+	}
+	return 1
+}
+
+func (s *ServerImplementation) GetAvailability(ctx echo.Context, params api.GetAvailabilityParams) error {
+	end := time.Now()
+	if params.End != nil {
+		end = *params.End
+	}
+	start := end.AddDate(0, 0, -30)
+	if params.Start != nil {
+		start = *params.Start
+	}
+	if !start.Before(end) {
+		return echo.NewHTTPError(http.StatusBadRequest, "expected start to be before end")
+	}
+	excludeMaintenance := s.Availability.ExcludeMaintenance
+	if params.ExcludeMaintenance != nil {
+		excludeMaintenance = *params.ExcludeMaintenance
+	}
+
+	components, err := s.Components(ctx.Request().Context())
+	if err != nil {
+		return upstreamError(ctx, err)
+	}
+	incidents, err := s.Incidents(ctx.Request().Context(), ctx.Logger())
+	if err != nil {
+		return upstreamError(ctx, err)
+	}
+
+	now := time.Now()
+	intervalsByComponent := map[string][]weightedInterval{}
+	for _, incident := range incidents {
+		if incident.BeganAt == nil {
+			continue
+		}
+		interval := weightedInterval{
+			Start:    *incident.BeganAt,
+			End:      now,
+			Weight:   s.impactTypeWeight(incident.ImpactType),
+			Excluded: excludeMaintenance && s.IsMaintenance(incident),
+		}
+		if incident.EndedAt != nil {
+			interval.End = *incident.EndedAt
+		} else if incident.Phase == s.LastPhase {
+			// Resolved, but without a known end
+			continue
+		}
+		for _, componentId := range incident.Affects {
+			intervalsByComponent[componentId] = append(intervalsByComponent[componentId], interval)
+		}
+	}
+
+	availabilities := []api.ComponentAvailability{}
+	for _, component := range components {
+		downtime, excluded := calculateAvailability(intervalsByComponent[component.Id], start, end)
+		availability := 100.0
+		if remaining := end.Sub(start) - excluded; remaining > 0 {
+			availability = 100 * (1 - float64(downtime)/float64(remaining))
+		}
+		availabilities = append(availabilities, api.ComponentAvailability{
+			ComponentId:     component.Id,
+			Start:           start,
+			End:             end,
+			Availability:    availability,
+			DowntimeSeconds: downtime.Seconds(),
+			ExcludedSeconds: excluded.Seconds(),
+		})
+	}
+	return ctx.JSON(200, availabilities)
+}
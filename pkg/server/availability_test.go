@@ -0,0 +1,82 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalculateAvailability(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(10 * time.Hour)
+	at := func(hours float64) time.Time {
+		return start.Add(time.Duration(hours * float64(time.Hour)))
+	}
+	tests := []struct {
+		name      string
+		intervals []weightedInterval
+		downtime  time.Duration
+		excluded  time.Duration
+	}{
+		{
+			name: "no intervals",
+		},
+		{
+			name:      "single interval",
+			intervals: []weightedInterval{{Start: at(1), End: at(3), Weight: 1}},
+			downtime:  2 * time.Hour,
+		},
+		{
+			name:      "weighted interval",
+			intervals: []weightedInterval{{Start: at(1), End: at(3), Weight: 0.5}},
+			downtime:  time.Hour,
+		},
+		{
+			name:      "clipped to time frame",
+			intervals: []weightedInterval{{Start: at(-2), End: at(1), Weight: 1}, {Start: at(9), End: at(12), Weight: 1}},
+			downtime:  2 * time.Hour,
+		},
+		{
+			name: "overlapping intervals count the highest weight",
+			intervals: []weightedInterval{
+				{Start: at(1), End: at(5), Weight: 0.5},
+				{Start: at(2), End: at(3), Weight: 1},
+			},
+			downtime: 2*time.Hour + 30*time.Minute,
+		},
+		{
+			name: "overlapping intervals of equal weight count once",
+			intervals: []weightedInterval{
+				{Start: at(1), End: at(3), Weight: 1},
+				{Start: at(2), End: at(4), Weight: 1},
+			},
+			downtime: 3 * time.Hour,
+		},
+		{
+			name:      "excluded interval",
+			intervals: []weightedInterval{{Start: at(1), End: at(3), Excluded: true}},
+			excluded:  2 * time.Hour,
+		},
+		{
+			name: "excluded interval takes precedence",
+			intervals: []weightedInterval{
+				{Start: at(1), End: at(4), Weight: 1},
+				{Start: at(3), End: at(5), Excluded: true},
+			},
+			downtime: 2 * time.Hour,
+			excluded: 2 * time.Hour,
+		},
+		{
+			name:      "ongoing interval until end",
+			intervals: []weightedInterval{{Start: at(8), End: at(20), Weight: 1}},
+			downtime:  2 * time.Hour,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			downtime, excluded := calculateAvailability(test.intervals, start, end)
+			if downtime != test.downtime || excluded != test.excluded {
+				t.Errorf("expected downtime %s and excluded %s; got %s and %s", test.downtime, test.excluded, downtime, excluded)
+			}
+		})
+	}
+}
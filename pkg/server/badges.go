@@ -0,0 +1,102 @@
+package server
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/joshmue/scs-status-page-openapi/pkg/api"
+	"github.com/labstack/echo/v4"
+)
+
+// badgeCacheControl lets image proxies cache badges briefly, so they still reflect outages timely.
+const badgeCacheControl = "public, max-age=60, s-maxage=60"
+
+// Badge colors by Statuspage component status.
+var badgeColors = map[string]string{
+	StatuspageOperational:         "#4c1",
+	StatuspageUnderMaintenance:    "#007ec6",
+	StatuspageDegradedPerformance: "#dfb317",
+	StatuspagePartialOutage:       "#fe7d37",
+	StatuspageMajorOutage:         "#e05d44",
+}
+
+// RegisterBadgeHandlers adds SVG status badges for the overall status and every component.
+func (s *ServerImplementation) RegisterBadgeHandlers(router api.EchoRouter) {
+	router.GET("/badge.svg", s.GetBadge)
+	router.GET("/components/:componentId/badge.svg", s.GetComponentBadge)
+}
+
+// badgeTextWidth approximates the width of text rendered in 11px Verdana.
+func badgeTextWidth(text string) int {
+	return len([]rune(text))*7 + 10
+}
+
+// renderBadge renders a shields-style badge with a gray label and a colored message.
+func renderBadge(label string, message string, color string) []byte {
+	labelWidth, messageWidth := badgeTextWidth(label), badgeTextWidth(message)
+	width := labelWidth + messageWidth
+	label, message = html.EscapeString(label), html.EscapeString(message)
+	return []byte(fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[4]s: %[5]s">
+<title>%[4]s: %[5]s</title>
+<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>
+<clipPath id="r"><rect width="%[1]d" height="20" rx="3" fill="#fff"/></clipPath>
+<g clip-path="url(#r)"><rect width="%[2]d" height="20" fill="#555"/><rect x="%[2]d" width="%[3]d" height="20" fill="%[6]s"/><rect width="%[1]d" height="20" fill="url(#s)"/></g>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="%[7]d" y="15" fill="#010101" fill-opacity=".3">%[4]s</text><text x="%[7]d" y="14">%[4]s</text>
+<text x="%[8]d" y="15" fill="#010101" fill-opacity=".3">%[5]s</text><text x="%[8]d" y="14">%[5]s</text>
+</g>
+</svg>
+`, width, labelWidth, messageWidth, label, message, color, labelWidth/2, labelWidth+messageWidth/2))
+}
+
+func writeBadge(ctx echo.Context, label string, message string, class string) error {
+	ctx.Response().Header().Set("Cache-Control", badgeCacheControl)
+	return ctx.Blob(200, "image/svg+xml; charset=utf-8", renderBadge(label, message, badgeColors[class]))
+}
+
+// badgeStatuses returns all components and their statuses, preferring the latest snapshot.
+func (s *ServerImplementation) badgeStatuses(ctx echo.Context) ([]api.Component, map[string]string, error) {
+	if snapshot := s.LatestSnapshot(); snapshot != nil {
+		return snapshot.Components, snapshot.ComponentStatuses, nil
+	}
+	components, err := s.Components(ctx.Request().Context())
+	if err != nil {
+		return nil, nil, err
+	}
+	incidents, err := s.Incidents(ctx.Request().Context(), ctx.Logger())
+	if err != nil {
+		return nil, nil, err
+	}
+	return components, s.ComponentStatuses(components, incidents, time.Now()), nil
+}
+
+func (s *ServerImplementation) GetBadge(ctx echo.Context) error {
+	components, statuses, err := s.badgeStatuses(ctx)
+	if err != nil {
+		return upstreamError(ctx, err)
+	}
+	class := StatuspageOperational
+	for _, component := range components {
+		componentClass := s.pageStatusClass(statuses[component.Id])
+		if statuspageComponentStatusSeverity[componentClass] > statuspageComponentStatusSeverity[class] {
+			class = componentClass
+		}
+	}
+	return writeBadge(ctx, s.Statuspage.PageName, strings.ReplaceAll(class, "_", " "), class)
+}
+
+func (s *ServerImplementation) GetComponentBadge(ctx echo.Context) error {
+	components, statuses, err := s.badgeStatuses(ctx)
+	if err != nil {
+		return upstreamError(ctx, err)
+	}
+	for _, component := range components {
+		if component.Id == ctx.Param("componentId") {
+			status := statuses[component.Id]
+			return writeBadge(ctx, component.DisplayName, status, s.pageStatusClass(status))
+		}
+	}
+	return echo.NewHTTPError(404)
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/joshmue/scs-status-page-openapi/pkg/api"
+	"github.com/labstack/echo/v4"
+)
+
+func TestBadges(t *testing.T) {
+	s := &ServerImplementation{
+		Statuspage: StatuspageConfig{
+			PageName:         "Example & Co",
+			ImpactTypeStatus: map[string]string{"connectivity-issues": StatuspageMajorOutage},
+		},
+		snapshot: &Snapshot{
+			Components: []api.Component{{Id: "api", DisplayName: "API"}, {Id: "web", DisplayName: "<Web>"}},
+			ComponentStatuses: map[string]string{
+				"api": "connectivity-issues",
+				"web": ComponentOperational,
+			},
+		},
+	}
+	e := echo.New()
+	s.RegisterBadgeHandlers(e)
+	for _, test := range []struct {
+		path     string
+		code     int
+		expected []string
+	}{
+		{"/badge.svg", 200, []string{`aria-label="Example &amp; Co: major outage"`, badgeColors[StatuspageMajorOutage]}},
+		{"/components/api/badge.svg", 200, []string{`aria-label="API: connectivity-issues"`, badgeColors[StatuspageMajorOutage]}},
+		{"/components/web/badge.svg", 200, []string{`aria-label="&lt;Web&gt;: ` + ComponentOperational + `"`, badgeColors[StatuspageOperational]}},
+		{"/components/unknown/badge.svg", http.StatusNotFound, nil},
+	} {
+		t.Run(test.path, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			e.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, test.path, nil))
+			if recorder.Code != test.code {
+				t.Fatalf("expected status %d; got %d", test.code, recorder.Code)
+			}
+			if test.code != 200 {
+				return
+			}
+			if contentType := recorder.Header().Get(echo.HeaderContentType); !strings.HasPrefix(contentType, "image/svg+xml") {
+				t.Errorf("expected an SVG; got %s", contentType)
+			}
+			if cacheControl := recorder.Header().Get("Cache-Control"); cacheControl != badgeCacheControl {
+				t.Errorf("expected Cache-Control %q; got %q", badgeCacheControl, cacheControl)
+			}
+			for _, expected := range test.expected {
+				if !strings.Contains(recorder.Body.String(), expected) {
+					t.Errorf("expected the badge to contain %q; got %s", expected, recorder.Body.String())
+				}
+			}
+		})
+	}
+}
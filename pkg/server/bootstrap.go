@@ -0,0 +1,385 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/shurcooL/githubv4"
+)
+
+// BootstrapConfig describes what the bootstrap command provisions in addition to what the server expects.
+type BootstrapConfig struct {
+	// ProjectTitle is the title of the project created if the owner has none with the configured number.
+	ProjectTitle string
+	// Phases are the options of the phase field, in order. The last phase is appended if missing.
+	Phases []string
+	// Repository ("<owner>/<name>") is linked to the project and holds the component labels.
+	Repository string
+	// Components are created as labels "<component prefix><name>" if missing.
+	Components []string
+	// DryRun only prints the planned changes.
+	DryRun bool
+}
+
+// Inputs of mutations not covered by githubv4. Their names have to match the GraphQL input types.
+type (
+	CreateProjectV2FieldInput struct {
+		ProjectID           githubv4.ID                             `json:"projectId"`
+		DataType            string                                  `json:"dataType"`
+		Name                string                                  `json:"name"`
+		SingleSelectOptions []ProjectV2SingleSelectFieldOptionInput `json:"singleSelectOptions,omitempty"`
+	}
+	UpdateProjectV2FieldInput struct {
+		FieldID             githubv4.ID                             `json:"fieldId"`
+		SingleSelectOptions []ProjectV2SingleSelectFieldOptionInput `json:"singleSelectOptions"`
+	}
+	ProjectV2SingleSelectFieldOptionInput struct {
+		Name        string `json:"name"`
+		Color       string `json:"color"`
+		Description string `json:"description"`
+	}
+	CreateLabelInput struct {
+		RepositoryID githubv4.ID `json:"repositoryId"`
+		Name         string      `json:"name"`
+		Color        string      `json:"color"`
+		Description  string      `json:"description"`
+	}
+)
+
+// bootstrapOptions plans how to extend the existing options of a field by the desired ones.
+// Updating a field replaces all of its options, which GitHub matches to the existing ones by
+// name, so existing options are kept as they are and missing ones are inserted in front of
+// the next desired option, or appended. Options which would have to be
+// reordered or removed are reported as problems instead. If last is set, it has to be the last option.
+func bootstrapOptions(field string, desired []string, existing []ProjectV2SingleSelectFieldOptionInput, last string, color func(option string) string) (options []ProjectV2SingleSelectFieldOptionInput, added []string, problems []string) {
+	if last != "" && !contains(desired, last) {
+		desired = append(desired, last)
+	}
+	names := []string{}
+	for _, option := range existing {
+		names = append(names, option.Name)
+	}
+	position := -1
+	for _, name := range names {
+		if !contains(desired, name) {
+			problems = append(problems, fmt.Sprintf(`option "%s" of field "%s" is not expected; remove it by hand once no item refers to it`, name, field))
+			continue
+		}
+		for i, option := range desired {
+			if option == name && i < position {
+				problems = append(problems, fmt.Sprintf(`option "%s" of field "%s" is out of order; expected the order "%s"`, name, field, strings.Join(desired, `", "`)))
+			} else if option == name {
+				position = i
+			}
+		}
+	}
+	if last != "" && len(names) > 0 && contains(names, last) && names[len(names)-1] != last {
+		problems = append(problems, fmt.Sprintf(`option "%s" of field "%s" is not the last one; move it to the end by hand`, last, field))
+	}
+
+	options = append(options, existing...)
+	for i, name := range desired {
+		if contains(names, name) {
+			continue
+		}
+		// Insert in front of the next desired option already present, or append
+		index := len(options)
+		for _, next := range desired[i+1:] {
+			found := false
+			for j, option := range options {
+				if option.Name == next {
+					index, found = j, true
+					break
+				}
+			}
+			if found {
+				break
+			}
+		}
+		option := ProjectV2SingleSelectFieldOptionInput{Name: name, Color: color(name)}
+		options = append(options[:index], append([]ProjectV2SingleSelectFieldOptionInput{option}, options[index:]...)...)
+		added = append(added, name)
+	}
+	return options, added, problems
+}
+
+// Bootstrap creates the project if needed and creates or fixes the fields, options,
+// repository link and component labels the server expects, printing every change to out.
+// Fields with the wrong data type and options in the wrong order are reported instead of being
+// replaced, as that would lose the values of items.
+func (s *ServerImplementation) Bootstrap(ctx context.Context, config BootstrapConfig, out io.Writer) error {
+	if s.ProjectOwnerIsOrg {
+		return fmt.Errorf("support for organizations owning projects not yet implemented")
+	}
+	apply := func(change string, mutate func() error) error {
+		if config.DryRun {
+			fmt.Fprintf(out, "would %s\n", change)
+			return nil
+		}
+		fmt.Fprintf(out, "%s\n", change)
+		return mutate()
+	}
+
+	// Project
+	var ownerQuery struct {
+		User struct {
+			Id         string
+			ProjectsV2 struct {
+				Nodes []struct {
+					Id     string
+					Number int64
+				}
+			} `graphql:"projectsV2(first: 100)"`
+		} `graphql:"user(login: $user)"`
+	}
+	err := s.GithubV4Client.Query(ctx, &ownerQuery, map[string]interface{}{
+		"user": githubv4.String(s.ProjectOwner),
+	})
+	if err != nil {
+		return err
+	}
+	s.ProjectID = ""
+	for _, project := range ownerQuery.User.ProjectsV2.Nodes {
+		if project.Number == s.ProjectNumber {
+			s.ProjectID = project.Id
+		}
+	}
+	if s.ProjectID == "" {
+		err := apply(fmt.Sprintf(`create project "%s" owned by "%s"`, config.ProjectTitle, s.ProjectOwner), func() error {
+			var mutation struct {
+				CreateProjectV2 struct {
+					ProjectV2 struct {
+						Id     string
+						Number int64
+					}
+				} `graphql:"createProjectV2(input: $input)"`
+			}
+			err := s.GithubV4Client.Mutate(ctx, &mutation, githubv4.CreateProjectV2Input{
+				OwnerID: githubv4.ID(ownerQuery.User.Id),
+				Title:   githubv4.String(config.ProjectTitle),
+			}, nil)
+			if err != nil {
+				return err
+			}
+			s.ProjectID = mutation.CreateProjectV2.ProjectV2.Id
+			s.ProjectNumber = mutation.CreateProjectV2.ProjectV2.Number
+			fmt.Fprintf(out, "created project %d; pass -github.project.number=%d to the server\n", s.ProjectNumber, s.ProjectNumber)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Fields
+	fields := map[string]*projectField{}
+	if s.ProjectID != "" {
+		var fieldQuery struct {
+			Node struct {
+				ProjectV2 struct {
+					Fields struct {
+						Nodes []projectFieldFragments
+					} `graphql:"fields(first: 50)"`
+				} `graphql:"... on ProjectV2"`
+			} `graphql:"node(id: $projectid)"`
+		}
+		err := s.GithubV4Client.Query(ctx, &fieldQuery, map[string]interface{}{
+			"projectid": githubv4.ID(s.ProjectID),
+		})
+		if err != nil {
+			return err
+		}
+		for i := range fieldQuery.Node.ProjectV2.Fields.Nodes {
+			if field := fieldQuery.Node.ProjectV2.Fields.Nodes[i].field(); field != nil {
+				fields[field.Name] = field
+			}
+		}
+	}
+
+	problems := []string{}
+	phases := append([]string{}, config.Phases...)
+	if s.Maintenance.ImpactType != "" && s.Maintenance.InProgressPhase != "" && !contains(phases, s.Maintenance.InProgressPhase) {
+		phases = append(phases, s.Maintenance.InProgressPhase)
+	}
+	impactTypes := append([]string{}, s.ImpactTypes...)
+	if s.Maintenance.ImpactType != "" && !contains(impactTypes, s.Maintenance.ImpactType) {
+		impactTypes = append(impactTypes, s.Maintenance.ImpactType)
+	}
+	for _, required := range []struct {
+		name     string
+		dataType string
+		options  []string
+		last     string
+		color    func(option string) string
+		// accepted lists further data types the field may have
+		accepted []string
+	}{
+		{s.Fields.Phase, "SINGLE_SELECT", phases, s.LastPhase, func(option string) string {
+			if option == s.LastPhase {
+				return "GREEN"
+			}
+			return "GRAY"
+		}, nil},
+		{s.Fields.ImpactType, "SINGLE_SELECT", impactTypes, "", func(option string) string {
+			if option == s.Maintenance.ImpactType {
+				return "BLUE"
+			}
+			if severity := s.impactTypeSeverity(option); severity > 0 && severity < len(s.ImpactTypes) {
+				return "ORANGE"
+			} else if severity > 0 {
+				return "RED"
+			}
+			return "GRAY"
+		}, nil},
+		{s.Fields.BeganAt, "TEXT", nil, "", nil, []string{"DATE"}},
+		{s.Fields.EndedAt, "TEXT", nil, "", nil, []string{"DATE"}},
+		{s.Fields.BeganAtTime, "TEXT", nil, "", nil, nil},
+		{s.Fields.EndedAtTime, "TEXT", nil, "", nil, nil},
+	} {
+		required := required
+		if required.name == "" {
+			continue
+		}
+		field, ok := fields[required.name]
+		var options []ProjectV2SingleSelectFieldOptionInput
+		var added []string
+		if required.dataType == "SINGLE_SELECT" && (!ok || field.DataType == required.dataType) {
+			existing := []ProjectV2SingleSelectFieldOptionInput{}
+			if ok {
+				for _, option := range field.Options {
+					existing = append(existing, ProjectV2SingleSelectFieldOptionInput{
+						Name:        option.Name,
+						Color:       option.Color,
+						Description: option.Description,
+					})
+				}
+			}
+			var optionProblems []string
+			options, added, optionProblems = bootstrapOptions(required.name, required.options, existing, required.last, required.color)
+			problems = append(problems, optionProblems...)
+		}
+		switch {
+		case !ok:
+			change := fmt.Sprintf(`create %s field "%s"`, strings.ReplaceAll(strings.ToLower(required.dataType), "_", " "), required.name)
+			if len(added) > 0 {
+				change += fmt.Sprintf(` with options "%s"`, strings.Join(added, `", "`))
+			}
+			err = apply(change, func() error {
+				var mutation struct {
+					CreateProjectV2Field struct {
+						ClientMutationId *string
+					} `graphql:"createProjectV2Field(input: $input)"`
+				}
+				return s.GithubV4Client.Mutate(ctx, &mutation, CreateProjectV2FieldInput{
+					ProjectID:           githubv4.ID(s.ProjectID),
+					DataType:            required.dataType,
+					Name:                required.name,
+					SingleSelectOptions: options,
+				}, nil)
+			})
+		case field.DataType != required.dataType && !contains(required.accepted, field.DataType):
+			problems = append(problems, fmt.Sprintf(`field "%s" is "%s" instead of "%s"; delete it and run bootstrap again`, required.name, field.DataType, required.dataType))
+		case len(added) > 0:
+			change := fmt.Sprintf(`add options "%s" to field "%s"`, strings.Join(added, `", "`), required.name)
+			err = apply(change, func() error {
+				var mutation struct {
+					UpdateProjectV2Field struct {
+						ClientMutationId *string
+					} `graphql:"updateProjectV2Field(input: $input)"`
+				}
+				return s.GithubV4Client.Mutate(ctx, &mutation, UpdateProjectV2FieldInput{
+					FieldID:             githubv4.ID(field.Id),
+					SingleSelectOptions: options,
+				}, nil)
+			})
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// Repository and component labels
+	if config.Repository != "" {
+		owner, name, ok := strings.Cut(config.Repository, "/")
+		if !ok {
+			return fmt.Errorf(`expected repository "%s" to be "<owner>/<name>"`, config.Repository)
+		}
+		var repositoryQuery struct {
+			Repository struct {
+				Id         string
+				ProjectsV2 struct {
+					Nodes []struct {
+						Id string
+					}
+				} `graphql:"projectsV2(first: 100)"`
+			} `graphql:"repository(owner: $owner, name: $name)"`
+		}
+		err := s.GithubV4Client.Query(ctx, &repositoryQuery, map[string]interface{}{
+			"owner": githubv4.String(owner),
+			"name":  githubv4.String(name),
+		})
+		if err != nil {
+			return err
+		}
+		repository := repositoryQuery.Repository
+		linked := false
+		for _, project := range repository.ProjectsV2.Nodes {
+			linked = linked || (project.Id == s.ProjectID && s.ProjectID != "")
+		}
+		if !linked {
+			err := apply(fmt.Sprintf(`link repository "%s" to the project`, config.Repository), func() error {
+				var mutation struct {
+					LinkProjectV2ToRepository struct {
+						ClientMutationId *string
+					} `graphql:"linkProjectV2ToRepository(input: $input)"`
+				}
+				return s.GithubV4Client.Mutate(ctx, &mutation, githubv4.LinkProjectV2ToRepositoryInput{
+					ProjectID:    githubv4.ID(s.ProjectID),
+					RepositoryID: githubv4.ID(repository.Id),
+				}, nil)
+			})
+			if err != nil {
+				return err
+			}
+		}
+		repositoryLabels, err := s.repositoryLabels(ctx, repository.Id, nil, false)
+		if err != nil {
+			return err
+		}
+		labels := []string{}
+		for _, label := range repositoryLabels {
+			labels = append(labels, label.Name)
+		}
+		for _, component := range config.Components {
+			label := s.ComponentPrefix + component
+			if contains(labels, label) {
+				continue
+			}
+			err := apply(fmt.Sprintf(`create label "%s" in repository "%s"`, label, config.Repository), func() error {
+				var mutation struct {
+					CreateLabel struct {
+						ClientMutationId *string
+					} `graphql:"createLabel(input: $input)"`
+				}
+				return s.GithubV4Client.Mutate(ctx, &mutation, CreateLabelInput{
+					RepositoryID: githubv4.ID(repository.Id),
+					Name:         label,
+					Color:        "c5def5",
+					Description:  fmt.Sprintf(`Component "%s" of the status page`, component),
+				}, nil)
+			})
+			if err != nil {
+				return err
+			}
+		}
+	} else if len(config.Components) > 0 {
+		return fmt.Errorf("expected a repository to create the component labels in")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("bootstrapping left problems:\n- %s", strings.Join(problems, "\n- "))
+	}
+	return nil
+}
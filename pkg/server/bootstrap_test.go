@@ -0,0 +1,144 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBootstrapOptions(t *testing.T) {
+	gray := func(string) string { return "GRAY" }
+	existing := func(names ...string) []ProjectV2SingleSelectFieldOptionInput {
+		options := []ProjectV2SingleSelectFieldOptionInput{}
+		for _, name := range names {
+			options = append(options, ProjectV2SingleSelectFieldOptionInput{Name: name, Color: "RED", Description: "Kept"})
+		}
+		return options
+	}
+	for _, test := range []struct {
+		name     string
+		desired  []string
+		existing []ProjectV2SingleSelectFieldOptionInput
+		last     string
+		options  []string
+		added    []string
+		problems int
+	}{
+		{
+			name:    "new field",
+			desired: []string{"Investigating", "In Progress"},
+			last:    "Done",
+			options: []string{"Investigating", "In Progress", "Done"},
+			added:   []string{"Investigating", "In Progress", "Done"},
+		},
+		{
+			name:     "up to date",
+			desired:  []string{"Investigating", "Done"},
+			existing: existing("Investigating", "Done"),
+			last:     "Done",
+			options:  []string{"Investigating", "Done"},
+		},
+		{
+			name:     "missing options are inserted in order",
+			desired:  []string{"Investigating", "Identified", "In Progress", "Done"},
+			existing: existing("Todo", "In Progress", "Done"),
+			last:     "Done",
+			options:  []string{"Todo", "Investigating", "Identified", "In Progress", "Done"},
+			added:    []string{"Investigating", "Identified"},
+			problems: 1,
+		},
+		{
+			name:     "missing last option is appended",
+			desired:  []string{"a", "b"},
+			existing: existing("a"),
+			options:  []string{"a", "b"},
+			added:    []string{"b"},
+		},
+		{
+			name:     "wrong order is reported",
+			desired:  []string{"a", "b"},
+			existing: existing("b", "a"),
+			options:  []string{"b", "a"},
+			problems: 1,
+		},
+		{
+			name:     "last option not last is reported",
+			desired:  []string{"a"},
+			existing: existing("Done", "a"),
+			last:     "Done",
+			options:  []string{"Done", "a"},
+			problems: 2,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			options, added, problems := bootstrapOptions("Status", test.desired, test.existing, test.last, gray)
+			names := []string{}
+			for _, option := range options {
+				names = append(names, option.Name)
+				for _, before := range test.existing {
+					if before.Name == option.Name && option != before {
+						t.Errorf("existing option %q changed to %+v", before.Name, option)
+					}
+				}
+			}
+			if !reflect.DeepEqual(names, test.options) {
+				t.Errorf("options: got %q, want %q", names, test.options)
+			}
+			if len(added) != len(test.added) || (len(added) > 0 && !reflect.DeepEqual(added, test.added)) {
+				t.Errorf("added: got %q, want %q", added, test.added)
+			}
+			if len(problems) != test.problems {
+				t.Errorf("problems: got %q, want %d", problems, test.problems)
+			}
+		})
+	}
+}
+
+func TestBootstrapAddsOptionsByName(t *testing.T) {
+	var update map[string]interface{}
+	s := &ServerImplementation{
+		GithubV4Client: fakeGithub(t, func(query string, variables map[string]interface{}) string {
+			switch {
+			case strings.Contains(query, "projectsV2"):
+				return `{"data":{"user":{"id":"user","projectsV2":{"nodes":[{"id":"project","number":1}]}}}}`
+			case strings.Contains(query, "fields(first: 50)"):
+				return `{"data":{"node":{"fields":{"nodes":[
+					{"id":"status","name":"Status","dataType":"SINGLE_SELECT","options":[
+						{"id":"a","name":"Investigating","color":"RED","description":"Looking into it"},
+						{"id":"b","name":"Resolved","color":"PURPLE","description":""}]},
+					{"id":"impact","name":"Impact Type","dataType":"SINGLE_SELECT","options":[
+						{"id":"c","name":"outage","color":"RED","description":""}]},
+					{"id":"began","name":"Began At","dataType":"TEXT"},
+					{"id":"ended","name":"Ended At","dataType":"DATE"}]}}}}`
+			case strings.Contains(query, "updateProjectV2Field"):
+				update = variables["input"].(map[string]interface{})
+				return `{"data":{"updateProjectV2Field":{"clientMutationId":null}}}`
+			}
+			t.Errorf("unexpected query %s", query)
+			return `{"data":{}}`
+		}),
+		ProjectOwner:  "owner",
+		ProjectNumber: 1,
+		Fields:        DefaultFieldNames,
+		LastPhase:     "Resolved",
+		ImpactTypes:   []string{"outage"},
+	}
+	var out bytes.Buffer
+	if err := s.Bootstrap(context.Background(), BootstrapConfig{Phases: []string{"Investigating", "Identified"}}, &out); err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := json.Marshal(update)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"fieldId":"status","singleSelectOptions":[` +
+		`{"color":"RED","description":"Looking into it","name":"Investigating"},` +
+		`{"color":"GRAY","description":"","name":"Identified"},` +
+		`{"color":"PURPLE","description":"","name":"Resolved"}]}`
+	if string(encoded) != expected {
+		t.Errorf("expected the update\n%s\ngot\n%s", expected, encoded)
+	}
+}
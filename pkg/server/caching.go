@@ -0,0 +1,138 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// cachingMaxEntries bounds the number of request URIs whose modification times are remembered.
+const cachingMaxEntries = 10000
+
+// RouteCaching configures caching headers of a route.
+type RouteCaching struct {
+	// CacheControl is sent as "Cache-Control" header, if set
+	CacheControl string
+	// LastModified enables "Last-Modified" headers and "If-Modified-Since" requests
+	LastModified bool
+}
+
+// CachingConfig configures conditional GET requests.
+type CachingConfig struct {
+	// Routes maps route paths like "/components/:componentId" to their caching configuration.
+	// Only these routes receive ETags.
+	Routes map[string]RouteCaching
+}
+
+type cachingEntry struct {
+	etag       string
+	modifiedAt time.Time
+}
+
+// cachingStore remembers when the ETag of a request URI changed last, which is its modification time.
+type cachingStore struct {
+	mutex   sync.Mutex
+	entries map[string]cachingEntry
+}
+
+// modifiedAt returns when the response to the request URI changed to the given ETag.
+func (c *cachingStore) modifiedAt(requestURI string, etag string) time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if entry, ok := c.entries[requestURI]; ok && entry.etag == etag {
+		return entry.modifiedAt
+	}
+	if c.entries == nil || len(c.entries) >= cachingMaxEntries {
+		c.entries = map[string]cachingEntry{}
+	}
+	entry := cachingEntry{etag: etag, modifiedAt: time.Now().UTC().Truncate(time.Second)}
+	c.entries[requestURI] = entry
+	return entry.modifiedAt
+}
+
+// bufferedResponseWriter holds back a response, so headers depending on its body can be added.
+type bufferedResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+func (w *bufferedResponseWriter) Write(content []byte) (int, error) {
+	return w.body.Write(content)
+}
+
+// etagMatches reports whether an "If-None-Match" header matches the ETag, using weak comparison.
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ConditionalGetMiddleware adds strong ETags computed over the response bodies of the configured
+// routes, answers matching "If-None-Match" and "If-Modified-Since" requests with 304 and sets
+// "Cache-Control" and "Last-Modified" headers as configured per route.
+func (s *ServerImplementation) ConditionalGetMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		request := ctx.Request()
+		config, ok := s.Caching.Routes[ctx.Path()]
+		if !ok || (request.Method != http.MethodGet && request.Method != http.MethodHead) {
+			return next(ctx)
+		}
+		response := ctx.Response()
+		original := response.Writer
+		buffered := &bufferedResponseWriter{ResponseWriter: original, status: http.StatusOK}
+		response.Writer = buffered
+		err := next(ctx)
+		response.Writer = original
+		if err != nil && !response.Committed {
+			return err
+		}
+
+		header := response.Header()
+		if buffered.status == http.StatusOK {
+			sum := sha256.Sum256(buffered.body.Bytes())
+			etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+			header.Set("ETag", etag)
+			if config.CacheControl != "" {
+				header.Set("Cache-Control", config.CacheControl)
+			}
+			notModified := false
+			if config.LastModified {
+				modifiedAt := s.cachingStore.modifiedAt(request.RequestURI, etag)
+				header.Set("Last-Modified", modifiedAt.Format(http.TimeFormat))
+				if since, parseErr := http.ParseTime(request.Header.Get("If-Modified-Since")); parseErr == nil && request.Header.Get("If-None-Match") == "" {
+					notModified = !modifiedAt.After(since)
+				}
+			}
+			if ifNoneMatch := request.Header.Get("If-None-Match"); ifNoneMatch != "" {
+				notModified = etagMatches(ifNoneMatch, etag)
+			}
+			if notModified {
+				header.Del(echo.HeaderContentType)
+				header.Del(echo.HeaderContentLength)
+				response.Status = http.StatusNotModified
+				original.WriteHeader(http.StatusNotModified)
+				return err
+			}
+		}
+		original.WriteHeader(buffered.status)
+		if _, writeErr := original.Write(buffered.body.Bytes()); writeErr != nil {
+			ctx.Logger().Error(writeErr)
+		}
+		return err
+	}
+}
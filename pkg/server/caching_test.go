@@ -0,0 +1,95 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestConditionalGetMiddleware(t *testing.T) {
+	s := &ServerImplementation{
+		Caching: CachingConfig{
+			Routes: map[string]RouteCaching{
+				"/components/:componentId": {CacheControl: "no-cache", LastModified: true},
+				"/phases":                  {CacheControl: "public, max-age=300"},
+			},
+		},
+	}
+	e := echo.New()
+	e.Use(s.ConditionalGetMiddleware)
+	e.GET("/components/:componentId", func(ctx echo.Context) error {
+		return ctx.JSON(200, map[string]string{"id": ctx.Param("componentId")})
+	})
+	e.GET("/phases", func(ctx echo.Context) error {
+		return ctx.JSON(200, []string{"Investigating", "Resolved"})
+	})
+	e.GET("/incidents", func(ctx echo.Context) error {
+		return ctx.JSON(200, []string{})
+	})
+	e.GET("/missing", func(ctx echo.Context) error {
+		return echo.NewHTTPError(404)
+	})
+	serve := func(path string, headers map[string]string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		for name, value := range headers {
+			request.Header.Set(name, value)
+		}
+		recorder := httptest.NewRecorder()
+		e.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	first := serve("/components/a", nil)
+	etag, lastModified := first.Header().Get("ETag"), first.Header().Get("Last-Modified")
+	if first.Code != 200 || etag == "" || lastModified == "" || first.Header().Get("Cache-Control") != "no-cache" || first.Body.Len() == 0 {
+		t.Fatalf("expected a full response with caching headers; got %d %v", first.Code, first.Header())
+	}
+	other := serve("/components/b", nil)
+	if other.Header().Get("ETag") == etag {
+		t.Errorf("expected different bodies to have different ETags")
+	}
+
+	tests := []struct {
+		name         string
+		path         string
+		headers      map[string]string
+		status       int
+		hasETag      bool
+		hasBody      bool
+		cacheControl string
+	}{
+		{"matching ETag", "/components/a", map[string]string{"If-None-Match": etag}, 304, true, false, ""},
+		{"matching weak ETag in list", "/components/a", map[string]string{"If-None-Match": `"other", W/` + etag}, 304, true, false, ""},
+		{"wildcard ETag", "/components/a", map[string]string{"If-None-Match": "*"}, 304, true, false, ""},
+		{"stale ETag", "/components/a", map[string]string{"If-None-Match": `"stale"`}, 200, true, true, ""},
+		{"not modified since", "/components/a", map[string]string{"If-Modified-Since": lastModified}, 304, true, false, ""},
+		{"modified since", "/components/a", map[string]string{"If-Modified-Since": time.Unix(0, 0).UTC().Format(http.TimeFormat)}, 200, true, true, ""},
+		{"ETag takes precedence over date", "/components/a", map[string]string{"If-None-Match": `"stale"`, "If-Modified-Since": lastModified}, 200, true, true, ""},
+		{"route without Last-Modified", "/phases", map[string]string{"If-Modified-Since": lastModified}, 200, true, true, "public, max-age=300"},
+		{"unconfigured route", "/incidents", map[string]string{"If-None-Match": "*"}, 200, false, true, ""},
+		{"error response", "/missing", map[string]string{"If-None-Match": "*"}, 404, false, true, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := serve(test.path, test.headers)
+			if response.Code != test.status {
+				t.Errorf("expected status %d; got %d", test.status, response.Code)
+			}
+			if (response.Header().Get("ETag") != "") != test.hasETag {
+				t.Errorf("expected ETag: %t; got %v", test.hasETag, response.Header())
+			}
+			if (response.Body.Len() > 0) != test.hasBody {
+				t.Errorf("expected body: %t; got %q", test.hasBody, response.Body.String())
+			}
+			if test.status == 304 && response.Header().Get(echo.HeaderContentType) != "" {
+				t.Errorf("expected no content type on 304; got %v", response.Header())
+			}
+			if test.cacheControl != "" && response.Header().Get("Cache-Control") != test.cacheControl {
+				t.Errorf("expected Cache-Control %q; got %v", test.cacheControl, response.Header())
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/joshmue/scs-status-page-openapi/pkg/api"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// chatQueueSize is the number of chat messages waiting to be posted.
+	chatQueueSize = 1000
+	// chatMaxAttempts is the number of attempts per chat message before giving up.
+	chatMaxAttempts = 4
+	// slackHeaderLength is the maximum length of the text of Slack header blocks.
+	slackHeaderLength = 150
+)
+
+// chatInitialBackoff is the delay before the first retry of a chat message; it doubles with every retry.
+var chatInitialBackoff = 5 * time.Second
+
+// ChatChannel is an incoming webhook of a chat receiving incident notifications.
+type ChatChannel struct {
+	// Format is either "slack" or "matrix"
+	Format string `json:"format"`
+	URL    string `json:"url"`
+	// Components limits notifications to incidents affecting any of them, if set.
+	Components []api.Id `json:"components"`
+}
+
+// LoadChatChannels reads a JSON list of chat channels from a file.
+func LoadChatChannels(path string) ([]ChatChannel, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	channels := []ChatChannel{}
+	if err := json.Unmarshal(content, &channels); err != nil {
+		return nil, err
+	}
+	for _, channel := range channels {
+		if _, ok := chatRenderers[channel.Format]; !ok {
+			return nil, fmt.Errorf(`unknown chat format "%s"`, channel.Format)
+		}
+	}
+	return channels, nil
+}
+
+// chatNotification is the content of a chat message about an incident.
+type chatNotification struct {
+	Type          api.WebhookEventType
+	Incident      api.Incident
+	PreviousPhase string
+	// Components are the display names of the affected components
+	Components []string
+}
+
+func (n *chatNotification) headline() string {
+	switch n.Type {
+	case api.IncidentCreated:
+		return "New incident: " + n.Incident.Title
+	case api.IncidentResolved:
+		return "Resolved: " + n.Incident.Title
+	}
+	return "Update: " + n.Incident.Title
+}
+
+func (n *chatNotification) fields() [][2]string {
+	phase := n.Incident.Phase
+	if n.PreviousPhase != "" {
+		phase = n.PreviousPhase + " → " + n.Incident.Phase
+	}
+	return [][2]string{
+		{"Phase", phase},
+		{"Impact type", n.Incident.ImpactType},
+		{"Affected components", strings.Join(n.Components, ", ")},
+	}
+}
+
+// chatRenderers render notifications into the payload expected by the incoming webhooks of a chat.
+var chatRenderers = map[string]func(chatNotification) ([]byte, error){
+	"slack":  renderSlackMessage,
+	"matrix": renderMatrixMessage,
+}
+
+// renderSlackMessage renders a Block Kit message.
+func renderSlackMessage(n chatNotification) ([]byte, error) {
+	fields := []map[string]string{}
+	for _, field := range n.fields() {
+		fields = append(fields, map[string]string{
+			"type": "mrkdwn",
+			"text": fmt.Sprintf("*%s*\n%s", field[0], slackEscape(field[1])),
+		})
+	}
+	return json.Marshal(map[string]interface{}{
+		"text": slackEscape(n.headline()),
+		"blocks": []map[string]interface{}{
+			{
+				"type": "header",
+				"text": map[string]string{"type": "plain_text", "text": truncate(n.headline(), slackHeaderLength)},
+			},
+			{
+				"type":   "section",
+				"fields": fields,
+			},
+		},
+	})
+}
+
+// truncate shortens text to at most length characters, ending it with an ellipsis if shortened.
+func truncate(text string, length int) string {
+	runes := []rune(text)
+	if len(runes) <= length {
+		return text
+	}
+	return string(runes[:length-1]) + "…"
+}
+
+// slackEscape escapes the control characters of Slack's mrkdwn.
+func slackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// renderMatrixMessage renders the content of an m.room.message event with HTML formatting.
+func renderMatrixMessage(n chatNotification) ([]byte, error) {
+	plain := []string{n.headline()}
+	formatted := []string{"<strong>" + html.EscapeString(n.headline()) + "</strong>"}
+	for _, field := range n.fields() {
+		plain = append(plain, field[0]+": "+field[1])
+		formatted = append(formatted, "<em>"+html.EscapeString(field[0])+"</em>: "+html.EscapeString(field[1]))
+	}
+	return json.Marshal(map[string]string{
+		"msgtype":        "m.text",
+		"body":           strings.Join(plain, "\n"),
+		"format":         "org.matrix.custom.html",
+		"formatted_body": strings.Join(formatted, "<br>"),
+	})
+}
+
+type chatMessage struct {
+	Channel ChatChannel
+	Payload []byte
+	Attempt int
+}
+
+// RunChatNotifications posts notifications derived from events to the configured chat channels.
+// It blocks until ctx is done.
+func (s *ServerImplementation) RunChatNotifications(ctx context.Context, logger echo.Logger) {
+	queue := make(chan chatMessage, chatQueueSize)
+	s.events.listen(func(event Event) {
+		s.enqueueChatMessages(logger, queue, event)
+	})
+	client := &http.Client{Timeout: 10 * time.Second}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message := <-queue:
+			postChatMessageWithRetries(ctx, logger, client, queue, message)
+		}
+	}
+}
+
+// postChatMessageWithRetries posts a chat message, queueing it again with exponential backoff if that fails.
+func postChatMessageWithRetries(ctx context.Context, logger echo.Logger, client *http.Client, queue chan chatMessage, message chatMessage) {
+	err := postChatMessage(ctx, client, message)
+	if err == nil {
+		return
+	}
+	message.Attempt++
+	if message.Attempt >= chatMaxAttempts {
+		logger.Errorf("Giving up posting %s chat message: %s", message.Channel.Format, err)
+		return
+	}
+	logger.Warnf("Posting %s chat message failed, retrying: %s", message.Channel.Format, err)
+	time.AfterFunc(chatInitialBackoff<<(message.Attempt-1), func() {
+		select {
+		case queue <- message:
+		default:
+			logger.Errorf("Dropping %s chat message: queue is full", message.Channel.Format)
+		}
+	})
+}
+
+func (s *ServerImplementation) enqueueChatMessages(logger echo.Logger, queue chan chatMessage, event Event) {
+	eventType, ok := NotificationType(event)
+	if !ok {
+		return
+	}
+	displayNames := map[string]string{}
+	if snapshot := s.LatestSnapshot(); snapshot != nil {
+		for _, component := range snapshot.Components {
+			displayNames[component.Id] = component.DisplayName
+		}
+	}
+	notification := chatNotification{
+		Type:          eventType,
+		Incident:      *event.Incident,
+		PreviousPhase: event.PreviousPhase,
+		Components:    []string{},
+	}
+	for _, id := range event.Incident.Affects {
+		notification.Components = append(notification.Components, displayNames[id])
+	}
+	for _, channel := range s.ChatChannels {
+		if !incidentAffectsAny(*event.Incident, channel.Components) {
+			continue
+		}
+		payload, err := chatRenderers[channel.Format](notification)
+		if err != nil {
+			logger.Error(err)
+			continue
+		}
+		select {
+		case queue <- chatMessage{Channel: channel, Payload: payload}:
+		default:
+			logger.Errorf("Dropping %s chat message: queue is full", channel.Format)
+		}
+	}
+}
+
+func postChatMessage(ctx context.Context, client *http.Client, message chatMessage) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, message.Channel.URL, bytes.NewReader(message.Payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code %d", response.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/joshmue/scs-status-page-openapi/pkg/api"
+	"github.com/labstack/echo/v4"
+)
+
+func TestRenderSlackMessage(t *testing.T) {
+	payload, err := renderSlackMessage(chatNotification{
+		Type:       api.IncidentCreated,
+		Incident:   api.Incident{Title: strings.Repeat("ä", 200), Phase: "Investigating", ImpactType: "outage"},
+		Components: []string{"API <v2>", "Web & Mobile"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var message struct {
+		Blocks []struct {
+			Text   struct{ Text string }
+			Fields []struct{ Text string }
+		}
+	}
+	if err := json.Unmarshal(payload, &message); err != nil {
+		t.Fatal(err)
+	}
+	header := message.Blocks[0].Text.Text
+	if utf8.RuneCountInString(header) != slackHeaderLength || !strings.HasSuffix(header, "…") {
+		t.Errorf("expected the header to be truncated to %d characters; got %d: %s", slackHeaderLength, utf8.RuneCountInString(header), header)
+	}
+	if components := message.Blocks[1].Fields[2].Text; components != "*Affected components*\nAPI &lt;v2&gt;, Web &amp; Mobile" {
+		t.Errorf("expected escaped components; got %q", components)
+	}
+}
+
+func TestRenderMatrixMessage(t *testing.T) {
+	payload, err := renderMatrixMessage(chatNotification{
+		Type:          api.IncidentPhaseChanged,
+		Incident:      api.Incident{Title: "<API> down", Phase: "Identified", ImpactType: "outage"},
+		PreviousPhase: "Investigating",
+		Components:    []string{"API"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var message map[string]string
+	if err := json.Unmarshal(payload, &message); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "Update: <API> down\nPhase: Investigating → Identified\nImpact type: outage\nAffected components: API"; message["body"] != expected {
+		t.Errorf("expected body %q; got %q", expected, message["body"])
+	}
+	if !strings.HasPrefix(message["formatted_body"], "<strong>Update: &lt;API&gt; down</strong><br>") {
+		t.Errorf("expected an escaped formatted body; got %q", message["formatted_body"])
+	}
+}
+
+func TestPostChatMessageRetries(t *testing.T) {
+	defer func(backoff time.Duration) { chatInitialBackoff = backoff }(chatInitialBackoff)
+	chatInitialBackoff = time.Millisecond
+	for _, test := range []struct {
+		name     string
+		failures int
+		attempts int
+	}{
+		{"succeeding after failures", chatMaxAttempts - 1, chatMaxAttempts},
+		{"failing", chatMaxAttempts + 1, chatMaxAttempts},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			attempts := 0
+			target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				if attempts <= test.failures {
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}
+			}))
+			defer target.Close()
+			queue := make(chan chatMessage, 1)
+			message := chatMessage{Channel: ChatChannel{Format: "slack", URL: target.URL}, Payload: []byte("{}")}
+			for {
+				postChatMessageWithRetries(context.Background(), echo.New().Logger, target.Client(), queue, message)
+				select {
+				case message = <-queue:
+					continue
+				case <-time.After(100 * time.Millisecond):
+				}
+				break
+			}
+			if attempts != test.attempts {
+				t.Errorf("expected %d attempts; got %d", test.attempts, attempts)
+			}
+		})
+	}
+}
@@ -3,13 +3,14 @@ package server
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/joshmue/scs-status-page-openapi/pkg/api"
 	"github.com/labstack/echo/v4"
 	"github.com/shurcooL/githubv4"
 )
 
-func (l *projectLabel) ToComponent() api.Component {
+func (l *projectLabel) ToComponent(prefix string) api.Component {
 	affectedBy := []api.Id{}
 	for issue := range l.Issues.Nodes {
 		for projectItem := range l.Issues.Nodes[issue].ProjectItems.Nodes {
@@ -18,79 +19,260 @@ func (l *projectLabel) ToComponent() api.Component {
 	}
 	return api.Component{
 		AffectedBy:  affectedBy,
-		DisplayName: strings.TrimPrefix(l.Name, "component:"),
+		DisplayName: strings.TrimPrefix(l.Name, prefix),
 		Id:          l.Id,
 		Labels:      map[string]string{}, // TODO
 	}
 }
 
+// pageInfo tells whether a connection has further pages and where the next one starts.
+type pageInfo struct {
+	HasNextPage bool
+	EndCursor   githubv4.String
+}
+
 type projectLabel struct {
 	Id          string
 	Name        string
 	Description string
-	Issues      struct {
+	// Issues are only fetched if the variable $withissues is set; see completeIssues
+	Issues struct {
+		Nodes    []labelIssue
+		PageInfo pageInfo
+	} `graphql:"issues(first: 20) @include(if: $withissues)"`
+}
+
+type labelIssue struct {
+	Id           string
+	ProjectItems struct {
 		Nodes []struct {
-			ProjectItems struct {
-				Nodes []struct {
-					Id string
-				}
-			} `graphql:"projectItems(first:10)"`
+			Id string
 		}
-	} `graphql:"issues(first:10)"`
+		PageInfo pageInfo
+	} `graphql:"projectItems(first: 10)"`
 }
 
-func (s *ServerImplementation) GetComponent(ctx echo.Context, componentId string) error {
+// completeIssues fetches the issues and project items of the label beyond their first pages.
+// The first pages are kept small, as GitHub limits the number of nodes a query may return.
+func (s *ServerImplementation) completeIssues(ctx context.Context, label *projectLabel) error {
+	for label.Issues.PageInfo.HasNextPage {
+		var query struct {
+			Node struct {
+				Label struct {
+					Issues struct {
+						Nodes    []labelIssue
+						PageInfo pageInfo
+					} `graphql:"issues(first: 100, after: $cursor)"`
+				} `graphql:"... on Label"`
+			} `graphql:"node(id: $labelid)"`
+		}
+		err := s.GithubV4Client.Query(ctx, &query, map[string]interface{}{
+			"labelid": githubv4.ID(label.Id),
+			"cursor":  label.Issues.PageInfo.EndCursor,
+		})
+		if err != nil {
+			return err
+		}
+		label.Issues.Nodes = append(label.Issues.Nodes, query.Node.Label.Issues.Nodes...)
+		label.Issues.PageInfo = query.Node.Label.Issues.PageInfo
+	}
+	for i := range label.Issues.Nodes {
+		items := &label.Issues.Nodes[i].ProjectItems
+		for items.PageInfo.HasNextPage {
+			var query struct {
+				Node struct {
+					Issue struct {
+						ProjectItems struct {
+							Nodes []struct {
+								Id string
+							}
+							PageInfo pageInfo
+						} `graphql:"projectItems(first: 100, after: $cursor)"`
+					} `graphql:"... on Issue"`
+				} `graphql:"node(id: $issueid)"`
+			}
+			err := s.GithubV4Client.Query(ctx, &query, map[string]interface{}{
+				"issueid": githubv4.ID(label.Issues.Nodes[i].Id),
+				"cursor":  items.PageInfo.EndCursor,
+			})
+			if err != nil {
+				return err
+			}
+			items.Nodes = append(items.Nodes, query.Node.Issue.ProjectItems.Nodes...)
+			items.PageInfo = query.Node.Issue.ProjectItems.PageInfo
+		}
+	}
+	return nil
+}
+
+// repositoryLabels fetches the labels of a repository, starting after cursor if it is set.
+func (s *ServerImplementation) repositoryLabels(ctx context.Context, repositoryId string, cursor *githubv4.String, withIssues bool) ([]projectLabel, error) {
+	labels := []projectLabel{}
+	for {
+		var query struct {
+			Node struct {
+				Repository struct {
+					Labels struct {
+						Nodes    []projectLabel
+						PageInfo pageInfo
+					} `graphql:"labels(first: 100, after: $cursor)"`
+				} `graphql:"... on Repository"`
+			} `graphql:"node(id: $repositoryid)"`
+		}
+		err := s.GithubV4Client.Query(ctx, &query, map[string]interface{}{
+			"repositoryid": githubv4.ID(repositoryId),
+			"cursor":       cursor,
+			"withissues":   githubv4.Boolean(withIssues),
+		})
+		if err != nil {
+			return nil, err
+		}
+		labels = append(labels, query.Node.Repository.Labels.Nodes...)
+		if !query.Node.Repository.Labels.PageInfo.HasNextPage {
+			return labels, nil
+		}
+		cursor = githubv4.NewString(query.Node.Repository.Labels.PageInfo.EndCursor)
+	}
+}
+
+// Component fetches a single component by its label ID.
+// If there is no such component, the error wraps ErrNotFound.
+func (s *ServerImplementation) Component(ctx context.Context, componentId string) (api.Component, error) {
 	var query struct {
 		Node struct {
 			Label projectLabel `graphql:"... on Label"`
 		} `graphql:"node(id: $labelid)"`
 	}
 	err := s.GithubV4Client.Query(
-		context.Background(),
+		ctx,
 		&query,
 		map[string]interface{}{
-			"labelid": githubv4.ID(componentId),
+			"labelid":    githubv4.ID(componentId),
+			"withissues": githubv4.Boolean(true),
 		},
 	)
 	if err != nil {
-		ctx.Logger().Error(err)
-		return echo.NewHTTPError(500)
+		return api.Component{}, err
+	}
+	if query.Node.Label.Id == "" || !s.isComponentLabel(query.Node.Label.Name) {
+		return api.Component{}, notFoundError(`component "%s"`, componentId)
+	}
+	if err := s.completeIssues(ctx, &query.Node.Label); err != nil {
+		return api.Component{}, err
 	}
-	return ctx.JSON(200, query.Node.Label.ToComponent())
+	return query.Node.Label.ToComponent(s.ComponentPrefix), nil
 }
-func (s *ServerImplementation) GetComponents(ctx echo.Context) error {
-	var query struct {
-		Node struct {
-			ProjectV2 struct {
-				Repositories struct {
-					Nodes []struct {
-						Labels struct {
-							Nodes []projectLabel
-						} `graphql:"labels(first: 10)"`
+
+// componentLabels fetches all component labels of the repositories linked to the project,
+// along with the issues labeled with them if withIssues is set.
+func (s *ServerImplementation) componentLabels(ctx context.Context, withIssues bool) ([]projectLabel, error) {
+	labels := []projectLabel{}
+	variables := map[string]interface{}{
+		"projectid":        githubv4.ID(s.ProjectID),
+		"repositorycursor": (*githubv4.String)(nil),
+		"withissues":       githubv4.Boolean(withIssues),
+	}
+	for {
+		var query struct {
+			Node struct {
+				ProjectV2 struct {
+					Repositories struct {
+						Nodes []struct {
+							Id     string
+							Labels struct {
+								Nodes    []projectLabel
+								PageInfo pageInfo
+							} `graphql:"labels(first: 100)"`
+						}
+						PageInfo pageInfo
+					} `graphql:"repositories(first: 10, after: $repositorycursor)"`
+				} `graphql:"... on ProjectV2"`
+			} `graphql:"node(id: $projectid)"`
+		}
+		if err := s.GithubV4Client.Query(ctx, &query, variables); err != nil {
+			return nil, err
+		}
+		repositories := query.Node.ProjectV2.Repositories
+		for _, repository := range repositories.Nodes {
+			repositoryLabels := repository.Labels.Nodes
+			if repository.Labels.PageInfo.HasNextPage {
+				more, err := s.repositoryLabels(ctx, repository.Id, githubv4.NewString(repository.Labels.PageInfo.EndCursor), withIssues)
+				if err != nil {
+					return nil, err
+				}
+				repositoryLabels = append(repositoryLabels, more...)
+			}
+			for i := range repositoryLabels {
+				if !s.isComponentLabel(repositoryLabels[i].Name) {
+					continue
+				}
+				if withIssues {
+					if err := s.completeIssues(ctx, &repositoryLabels[i]); err != nil {
+						return nil, err
 					}
-				} `graphql:"repositories(first: 10)"`
-			} `graphql:"... on ProjectV2"`
-		} `graphql:"node(id: $projectid)"`
+				}
+				labels = append(labels, repositoryLabels[i])
+			}
+		}
+		if !repositories.PageInfo.HasNextPage {
+			return labels, nil
+		}
+		variables["repositorycursor"] = githubv4.NewString(repositories.PageInfo.EndCursor)
 	}
-	err := s.GithubV4Client.Query(
-		context.Background(),
-		&query,
-		map[string]interface{}{
-			"projectid": githubv4.ID(s.ProjectID),
-		},
-	)
+}
+
+// Components fetches all component labels of the repositories linked to the project.
+func (s *ServerImplementation) Components(ctx context.Context) ([]api.Component, error) {
+	labels, err := s.componentLabels(ctx, true)
 	if err != nil {
-		ctx.Logger().Error(err)
-		return echo.NewHTTPError(500)
+		return nil, err
 	}
 	components := []api.Component{}
-	for repo := range query.Node.ProjectV2.Repositories.Nodes {
-		for label := range query.Node.ProjectV2.Repositories.Nodes[repo].Labels.Nodes {
-			if !strings.HasPrefix(query.Node.ProjectV2.Repositories.Nodes[repo].Labels.Nodes[label].Name, "component:") {
+	for i := range labels {
+		components = append(components, labels[i].ToComponent(s.ComponentPrefix))
+	}
+	return components, nil
+}
+
+// ComponentOperational is the status of components not affected by any ongoing incident.
+const ComponentOperational = "operational"
+
+// ComponentStatuses derives the status of components from the incidents at the given time.
+// Components affected by ongoing incidents or maintenance windows have the most severe
+// impact type among them as status; all others are operational.
+func (s *ServerImplementation) ComponentStatuses(components []api.Component, incidents []api.Incident, now time.Time) map[string]string {
+	statuses := map[string]string{}
+	for _, component := range components {
+		statuses[component.Id] = ComponentOperational
+	}
+	for _, incident := range incidents {
+		if !s.IsOngoing(incident, now) {
+			continue
+		}
+		for _, componentId := range incident.Affects {
+			status, ok := statuses[componentId]
+			if !ok {
 				continue
 			}
-			components = append(components, query.Node.ProjectV2.Repositories.Nodes[repo].Labels.Nodes[label].ToComponent())
+			if status == ComponentOperational || s.impactTypeSeverity(incident.ImpactType) > s.impactTypeSeverity(status) {
+				statuses[componentId] = incident.ImpactType
+			}
 		}
 	}
+	return statuses
+}
+
+func (s *ServerImplementation) GetComponent(ctx echo.Context, componentId string) error {
+	component, err := s.Component(ctx.Request().Context(), componentId)
+	if err != nil {
+		return upstreamError(ctx, err)
+	}
+	return ctx.JSON(200, component)
+}
+func (s *ServerImplementation) GetComponents(ctx echo.Context) error {
+	components, err := s.Components(ctx.Request().Context())
+	if err != nil {
+		return upstreamError(ctx, err)
+	}
 	return ctx.JSON(200, components)
 }
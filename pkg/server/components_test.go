@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestComponentsPaginates(t *testing.T) {
+	s := &ServerImplementation{
+		GithubV4Client: fakeGithub(t, func(query string, variables map[string]interface{}) string {
+			switch {
+			case strings.Contains(query, "repositories(") && variables["repositorycursor"] == nil:
+				return `{"data":{"node":{"repositories":{"nodes":[{"id":"r1","labels":{"nodes":[
+					{"id":"bug","name":"bug","issues":{"nodes":[],"pageInfo":{"hasNextPage":false}}},
+					{"id":"api","name":"component:API","issues":{"nodes":[{"id":"i1","projectItems":{"nodes":[{"id":"item1"}],"pageInfo":{"hasNextPage":false}}}],"pageInfo":{"hasNextPage":true,"endCursor":"i1"}}}
+				],"pageInfo":{"hasNextPage":true,"endCursor":"l1"}}}],"pageInfo":{"hasNextPage":true,"endCursor":"r1"}}}}}`
+			case strings.Contains(query, "repositories("):
+				return `{"data":{"node":{"repositories":{"nodes":[{"id":"r2","labels":{"nodes":[
+					{"id":"web","name":"component:Web","issues":{"nodes":[],"pageInfo":{"hasNextPage":false}}}
+				],"pageInfo":{"hasNextPage":false}}}],"pageInfo":{"hasNextPage":false}}}}}`
+			case strings.Contains(query, "$repositoryid") && variables["repositoryid"] == "r1":
+				return `{"data":{"node":{"labels":{"nodes":[
+					{"id":"db","name":"component:Database","issues":{"nodes":[],"pageInfo":{"hasNextPage":false}}}
+				],"pageInfo":{"hasNextPage":false}}}}}`
+			case strings.Contains(query, "$labelid") && variables["labelid"] == "api":
+				return `{"data":{"node":{"issues":{"nodes":[
+					{"id":"i2","projectItems":{"nodes":[{"id":"item2"}],"pageInfo":{"hasNextPage":true,"endCursor":"p1"}}}
+				],"pageInfo":{"hasNextPage":false}}}}}`
+			case strings.Contains(query, "$issueid") && variables["issueid"] == "i2":
+				return `{"data":{"node":{"projectItems":{"nodes":[{"id":"item3"}],"pageInfo":{"hasNextPage":false}}}}}`
+			}
+			t.Errorf("unexpected query %s with %v", query, variables)
+			return `{"data":{}}`
+		}),
+		ProjectID:       "project",
+		ComponentPrefix: DefaultComponentPrefix,
+	}
+	components, err := s.Components(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, component := range components {
+		names = append(names, component.DisplayName)
+		if component.DisplayName == "API" && !reflect.DeepEqual(component.AffectedBy, []string{"item1", "item2", "item3"}) {
+			t.Errorf("expected all items affecting the API; got %v", component.AffectedBy)
+		}
+	}
+	sort.Strings(names)
+	if !reflect.DeepEqual(names, []string{"API", "Database", "Web"}) {
+		t.Errorf("expected components of all pages; got %v", names)
+	}
+}
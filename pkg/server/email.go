@@ -0,0 +1,481 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"net/url"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/joshmue/scs-status-page-openapi/pkg/api"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// emailQueueSize is the number of emails waiting to be sent.
+	emailQueueSize = 1000
+	// subscribeRateLimit is the number of subscription requests accepted per client and subscribeRateWindow.
+	subscribeRateLimit  = 5
+	subscribeRateWindow = time.Hour
+	// subscribeRateClients is the number of clients tracked; the oldest windows are dropped beyond it.
+	subscribeRateClients = 10000
+	// confirmResendInterval is how long no further confirmation email is sent while one is pending.
+	confirmResendInterval = 24 * time.Hour
+)
+
+//go:embed templates/email/*.tmpl
+var emailTemplateFS embed.FS
+
+// Every email template file defines a "subject" and a "body" template.
+var emailTemplates = map[string]*template.Template{
+	"confirm":  parseEmailTemplate("confirm"),
+	"incident": parseEmailTemplate("incident"),
+}
+
+func parseEmailTemplate(name string) *template.Template {
+	return template.Must(template.New(name).Funcs(template.FuncMap{
+		"join": strings.Join,
+	}).ParseFS(emailTemplateFS, "templates/email/"+name+".tmpl"))
+}
+
+// EmailConfig configures email subscriptions.
+type EmailConfig struct {
+	// SMTPAddr is the "host:port" of the SMTP server. If empty, email subscriptions are disabled.
+	SMTPAddr string
+	// Username and Password authenticate against the SMTP server, if set.
+	Username string
+	Password string
+	From     string
+	// StateFile persists subscribers across restarts, if set.
+	StateFile string
+}
+
+type subscriber struct {
+	api.Subscriber
+	// PendingComponents replace the components of a confirmed subscriber once the ConfirmToken is used
+	PendingComponents *[]api.Id `json:"pendingComponents,omitempty"`
+	ConfirmToken      string    `json:"confirmToken"`
+	// ConfirmSentAt is when the email with the pending ConfirmToken was sent
+	ConfirmSentAt    time.Time `json:"confirmSentAt"`
+	UnsubscribeToken string    `json:"unsubscribeToken"`
+}
+
+// email is a single message to be sent.
+type email struct {
+	To             string
+	Subject        string
+	Body           string
+	UnsubscribeURL string
+}
+
+// subscriberStore holds email subscribers and the queue of emails to be sent.
+type subscriberStore struct {
+	mutex       sync.Mutex
+	subscribers map[string]*subscriber
+	queue       chan email
+	// requests counts subscription requests per client within the current rate window
+	requests map[string]*subscribeRequests
+}
+
+type subscribeRequests struct {
+	windowStart time.Time
+	count       int
+}
+
+// allowSubscribe counts a subscription request of the client and reports whether it is
+// within the rate limit. The caller must hold the mutex.
+func (store *subscriberStore) allowSubscribe(client string, now time.Time) bool {
+	if store.requests == nil {
+		store.requests = map[string]*subscribeRequests{}
+	}
+	requests, ok := store.requests[client]
+	if !ok || now.Sub(requests.windowStart) >= subscribeRateWindow {
+		if !ok && len(store.requests) >= subscribeRateClients {
+			for key, other := range store.requests {
+				if now.Sub(other.windowStart) >= subscribeRateWindow {
+					delete(store.requests, key)
+				}
+			}
+			if len(store.requests) >= subscribeRateClients {
+				return false
+			}
+		}
+		requests = &subscribeRequests{windowStart: now}
+		store.requests[client] = requests
+	}
+	if requests.count >= subscribeRateLimit {
+		return false
+	}
+	requests.count++
+	return true
+}
+
+// renderEmail executes the "subject" and "body" templates of the named email template.
+func renderEmail(name string, data interface{}) (subject string, body string, err error) {
+	var buffer bytes.Buffer
+	if err := emailTemplates[name].ExecuteTemplate(&buffer, "subject", data); err != nil {
+		return "", "", err
+	}
+	subject = buffer.String()
+	buffer.Reset()
+	if err := emailTemplates[name].ExecuteTemplate(&buffer, "body", data); err != nil {
+		return "", "", err
+	}
+	return subject, buffer.String(), nil
+}
+
+func (s *ServerImplementation) loadSubscribers() error {
+	s.subscriberStore.mutex.Lock()
+	defer s.subscriberStore.mutex.Unlock()
+	s.subscriberStore.subscribers = map[string]*subscriber{}
+	return loadState(s.Email.StateFile, &s.subscriberStore.subscribers)
+}
+
+// saveSubscribers persists subscribers. The caller must hold the mutex.
+func (s *ServerImplementation) saveSubscribers() error {
+	return saveState(s.Email.StateFile, s.subscriberStore.subscribers)
+}
+
+// RunEmails loads subscribers and sends emails about notifications derived from events.
+// It blocks until ctx is done.
+func (s *ServerImplementation) RunEmails(ctx context.Context, logger echo.Logger) error {
+	if err := s.loadSubscribers(); err != nil {
+		return err
+	}
+	queue := make(chan email, emailQueueSize)
+	s.subscriberStore.mutex.Lock()
+	s.subscriberStore.queue = queue
+	s.subscriberStore.mutex.Unlock()
+	s.events.listen(func(event Event) {
+		s.enqueueIncidentEmails(logger, event)
+	})
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case message := <-queue:
+			if err := s.sendEmail(message); err != nil {
+				logger.Errorf("Sending email to %s failed: %s", message.To, err)
+			}
+		}
+	}
+}
+
+// enqueueEmail queues an email without blocking. The caller must hold the mutex.
+func (s *ServerImplementation) enqueueEmail(logger echo.Logger, message email) {
+	select {
+	case s.subscriberStore.queue <- message:
+	default:
+		logger.Errorf("Dropping email to %s: queue is full", message.To)
+	}
+}
+
+func (s *ServerImplementation) enqueueIncidentEmails(logger echo.Logger, event Event) {
+	eventType, ok := NotificationType(event)
+	if !ok {
+		return
+	}
+	displayNames := map[string]string{}
+	if snapshot := s.LatestSnapshot(); snapshot != nil {
+		for _, component := range snapshot.Components {
+			displayNames[component.Id] = component.DisplayName
+		}
+	}
+	components := []string{}
+	for _, id := range event.Incident.Affects {
+		components = append(components, displayNames[id])
+	}
+	data := struct {
+		Type           api.WebhookEventType
+		Incident       *api.Incident
+		PreviousPhase  string
+		Components     []string
+		BeganAt        *time.Time
+		EndedAt        *time.Time
+		UnsubscribeURL string
+	}{
+		Type:          eventType,
+		Incident:      event.Incident,
+		PreviousPhase: event.PreviousPhase,
+		Components:    components,
+	}
+	if event.Incident.BeganAt != nil {
+		beganAt := event.Incident.BeganAt.In(s.TimeZone)
+		data.BeganAt = &beganAt
+	}
+	if event.Incident.EndedAt != nil {
+		endedAt := event.Incident.EndedAt.In(s.TimeZone)
+		data.EndedAt = &endedAt
+	}
+
+	s.subscriberStore.mutex.Lock()
+	defer s.subscriberStore.mutex.Unlock()
+	for _, subscriber := range s.subscriberStore.subscribers {
+		if !subscriber.Confirmed || !incidentAffectsAny(*event.Incident, subscriber.Components) {
+			continue
+		}
+		data.UnsubscribeURL = s.subscriberURL("unsubscribe", subscriber.UnsubscribeToken)
+		subject, body, err := renderEmail("incident", data)
+		if err != nil {
+			logger.Error(err)
+			return
+		}
+		s.enqueueEmail(logger, email{
+			To:             subscriber.Email,
+			Subject:        subject,
+			Body:           body,
+			UnsubscribeURL: data.UnsubscribeURL,
+		})
+	}
+}
+
+func (s *ServerImplementation) subscriberURL(action string, token string) string {
+	return s.publicLink(fmt.Sprintf("/subscribers/%s?token=%s", action, url.QueryEscape(token)))
+}
+
+func (s *ServerImplementation) sendEmail(message email) error {
+	from, err := mail.ParseAddress(s.Email.From)
+	if err != nil {
+		return err
+	}
+	domain := from.Address[strings.LastIndex(from.Address, "@")+1:]
+	headers := []string{
+		"From: " + s.Email.From,
+		"To: " + message.To,
+		"Subject: " + mime.QEncoding.Encode("UTF-8", message.Subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"Message-ID: <" + newId() + "@" + domain + ">",
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"Content-Transfer-Encoding: 8bit",
+	}
+	if message.UnsubscribeURL != "" {
+		headers = append(headers, "List-Unsubscribe: <"+message.UnsubscribeURL+">", "List-Unsubscribe-Post: List-Unsubscribe=One-Click")
+	}
+	body := strings.ReplaceAll(message.Body, "\n", "\r\n")
+	content := strings.Join(headers, "\r\n") + "\r\n\r\n" + body
+
+	var auth smtp.Auth
+	if s.Email.Username != "" {
+		host := strings.Split(s.Email.SMTPAddr, ":")[0]
+		auth = smtp.PlainAuth("", s.Email.Username, s.Email.Password, host)
+	}
+	return smtp.SendMail(s.Email.SMTPAddr, auth, from.Address, []string{message.To}, []byte(content))
+}
+
+func (s *ServerImplementation) GetSubscribers(ctx echo.Context) error {
+	if err := s.authorize(ctx); err != nil {
+		return err
+	}
+	s.subscriberStore.mutex.Lock()
+	defer s.subscriberStore.mutex.Unlock()
+	subscribers := []api.Subscriber{}
+	for _, subscriber := range s.subscriberStore.subscribers {
+		subscribers = append(subscribers, subscriber.Subscriber)
+	}
+	return ctx.JSON(200, subscribers)
+}
+
+// CreateSubscriber sends a confirmation email to the subscribing address. Subscribing
+// again changes the components of confirmed subscribers only once confirmed. While a
+// confirmation is pending, no further email is sent to the address, and requests are
+// rate limited per client, so the endpoint cannot be used to flood inboxes.
+func (s *ServerImplementation) CreateSubscriber(ctx echo.Context) error {
+	var body api.CreateSubscriberJSONRequestBody
+	if err := ctx.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	address, err := mail.ParseAddress(body.Email)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid email address")
+	}
+	components := []api.Id{}
+	if body.Components != nil {
+		components = *body.Components
+	}
+	if len(components) > 0 {
+		known, err := s.knownComponentIds(ctx.Request().Context())
+		if err != nil {
+			return upstreamError(ctx, err)
+		}
+		for _, component := range components {
+			if !contains(known, component) {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("unknown component %q", component))
+			}
+		}
+	}
+
+	s.subscriberStore.mutex.Lock()
+	defer s.subscriberStore.mutex.Unlock()
+	if s.subscriberStore.queue == nil {
+		return echo.NewHTTPError(http.StatusNotImplemented, "email subscriptions are disabled")
+	}
+	now := time.Now()
+	if !s.subscriberStore.allowSubscribe(ctx.RealIP(), now) {
+		return echo.NewHTTPError(http.StatusTooManyRequests, "too many subscription requests")
+	}
+	var subscription *subscriber
+	for _, existing := range s.subscriberStore.subscribers {
+		if existing.Email == address.Address {
+			subscription = existing
+		}
+	}
+	if subscription != nil && subscription.ConfirmToken != "" && now.Sub(subscription.ConfirmSentAt) < confirmResendInterval {
+		// The pending confirmation email is still valid; do not send another one
+		return ctx.NoContent(202)
+	}
+	switch {
+	case subscription == nil:
+		subscription = &subscriber{
+			Subscriber: api.Subscriber{
+				Id:         newId(),
+				Email:      address.Address,
+				Components: components,
+				CreatedAt:  now,
+			},
+			UnsubscribeToken: newId(),
+		}
+		s.subscriberStore.subscribers[subscription.Id] = subscription
+	case subscription.Confirmed:
+		// Anyone may subscribe any address, so confirmed subscriptions only change once confirmed again
+		subscription.PendingComponents = &components
+	default:
+		subscription.Components = components
+	}
+	subscription.ConfirmToken = newId()
+	subscription.ConfirmSentAt = now
+	if err := s.saveSubscribers(); err != nil {
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(500)
+	}
+	subject, text, err := renderEmail("confirm", map[string]string{
+		"Email":      subscription.Email,
+		"ConfirmURL": s.subscriberURL("confirm", subscription.ConfirmToken),
+	})
+	if err != nil {
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(500)
+	}
+	s.enqueueEmail(ctx.Logger(), email{To: subscription.Email, Subject: subject, Body: text})
+	return ctx.NoContent(202)
+}
+
+// knownComponentIds returns the IDs of all components, preferably from the latest snapshot.
+func (s *ServerImplementation) knownComponentIds(ctx context.Context) ([]string, error) {
+	components := []api.Component{}
+	if snapshot := s.LatestSnapshot(); snapshot != nil {
+		components = snapshot.Components
+	} else {
+		var err error
+		if components, err = s.Components(ctx); err != nil {
+			return nil, err
+		}
+	}
+	ids := []string{}
+	for _, component := range components {
+		ids = append(ids, component.Id)
+	}
+	return ids, nil
+}
+
+// ShowSubscriberConfirmation asks to confirm a subscription. Only posting the form confirms it,
+// so link scanners of mail providers following the link do not.
+func (s *ServerImplementation) ShowSubscriberConfirmation(ctx echo.Context, params api.ShowSubscriberConfirmationParams) error {
+	if s.subscriberByToken(params.Token, func(subscriber *subscriber) string { return subscriber.ConfirmToken }) == nil {
+		return echo.NewHTTPError(404, "unknown token")
+	}
+	return s.renderPage(ctx, "subscriber", &pageData{
+		PageName:    s.Statuspage.PageName,
+		GeneratedAt: time.Now(),
+		Subscription: &pageSubscription{
+			Title:  "Confirm your subscription",
+			Action: "/subscribers/confirm",
+			Token:  params.Token,
+			Button: "Confirm",
+		},
+	})
+}
+
+func (s *ServerImplementation) ConfirmSubscriber(ctx echo.Context) error {
+	s.subscriberStore.mutex.Lock()
+	defer s.subscriberStore.mutex.Unlock()
+	token := ctx.FormValue("token")
+	for _, subscriber := range s.subscriberStore.subscribers {
+		if subscriber.ConfirmToken != "" && subscriber.ConfirmToken == token {
+			subscriber.Confirmed = true
+			if subscriber.PendingComponents != nil {
+				subscriber.Components = *subscriber.PendingComponents
+				subscriber.PendingComponents = nil
+			}
+			// Confirmation links only work once
+			subscriber.ConfirmToken = ""
+			if err := s.saveSubscribers(); err != nil {
+				ctx.Logger().Error(err)
+				return echo.NewHTTPError(500)
+			}
+			return ctx.String(200, "Your subscription is confirmed.")
+		}
+	}
+	return echo.NewHTTPError(404, "unknown token")
+}
+
+// ShowSubscriberUnsubscription asks to cancel a subscription. Only posting the form cancels it,
+// so link scanners of mail providers following the link do not.
+func (s *ServerImplementation) ShowSubscriberUnsubscription(ctx echo.Context, params api.ShowSubscriberUnsubscriptionParams) error {
+	if s.subscriberByToken(params.Token, func(subscriber *subscriber) string { return subscriber.UnsubscribeToken }) == nil {
+		return echo.NewHTTPError(404, "unknown token")
+	}
+	return s.renderPage(ctx, "subscriber", &pageData{
+		PageName:    s.Statuspage.PageName,
+		GeneratedAt: time.Now(),
+		Subscription: &pageSubscription{
+			Title:  "Cancel your subscription",
+			Action: "/subscribers/unsubscribe",
+			Token:  params.Token,
+			Button: "Unsubscribe",
+		},
+	})
+}
+
+// UnsubscribeSubscriber cancels a subscription. The token is taken from the query for
+// one-click unsubscribing (RFC 8058) and from the form otherwise.
+func (s *ServerImplementation) UnsubscribeSubscriber(ctx echo.Context, params api.UnsubscribeSubscriberParams) error {
+	token := ctx.FormValue("token")
+	if params.Token != nil {
+		token = *params.Token
+	}
+	s.subscriberStore.mutex.Lock()
+	defer s.subscriberStore.mutex.Unlock()
+	for id, subscriber := range s.subscriberStore.subscribers {
+		if subscriber.UnsubscribeToken != "" && subscriber.UnsubscribeToken == token {
+			delete(s.subscriberStore.subscribers, id)
+			if err := s.saveSubscribers(); err != nil {
+				s.subscriberStore.subscribers[id] = subscriber
+				ctx.Logger().Error(err)
+				return echo.NewHTTPError(500)
+			}
+			return ctx.String(200, "You are unsubscribed.")
+		}
+	}
+	return echo.NewHTTPError(404, "unknown token")
+}
+
+// subscriberByToken returns the subscriber whose token, as selected by tokenOf, is token.
+func (s *ServerImplementation) subscriberByToken(token string, tokenOf func(subscriber *subscriber) string) *subscriber {
+	s.subscriberStore.mutex.Lock()
+	defer s.subscriberStore.mutex.Unlock()
+	for _, subscriber := range s.subscriberStore.subscribers {
+		if tokenOf(subscriber) != "" && tokenOf(subscriber) == token {
+			return subscriber
+		}
+	}
+	return nil
+}
@@ -0,0 +1,298 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joshmue/scs-status-page-openapi/pkg/api"
+	"github.com/labstack/echo/v4"
+)
+
+// subscribe posts a subscription request with the given JSON body from the given client
+// and returns the response status.
+func subscribe(t *testing.T, s *ServerImplementation, client string, body string) int {
+	t.Helper()
+	request := httptest.NewRequest(http.MethodPost, "/subscribers", strings.NewReader(body))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	request.RemoteAddr = client + ":1234"
+	recorder := httptest.NewRecorder()
+	e := echo.New()
+	ctx := e.NewContext(request, recorder)
+	if err := s.CreateSubscriber(ctx); err != nil {
+		e.HTTPErrorHandler(err, ctx)
+	}
+	return recorder.Code
+}
+
+// subscriptionServer returns a server with email subscriptions enabled and components "a" and "b".
+func subscriptionServer() *ServerImplementation {
+	s := &ServerImplementation{snapshot: &Snapshot{Components: []api.Component{{Id: "a"}, {Id: "b"}}}}
+	s.subscriberStore.subscribers = map[string]*subscriber{}
+	s.subscriberStore.queue = make(chan email, 10)
+	return s
+}
+
+// confirm posts the confirmation form with the given token.
+func confirm(t *testing.T, s *ServerImplementation, token string) {
+	t.Helper()
+	request := httptest.NewRequest(http.MethodPost, "/subscribers/confirm", strings.NewReader(url.Values{"token": {token}}.Encode()))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	if err := s.ConfirmSubscriber(echo.New().NewContext(request, httptest.NewRecorder())); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResubscribingKeepsConfirmedSubscription(t *testing.T) {
+	s := subscriptionServer()
+
+	subscribe(t, s, "192.0.2.1", `{"email": "ops@example.com", "components": ["a"]}`)
+	var subscription *subscriber
+	for _, entry := range s.subscriberStore.subscribers {
+		subscription = entry
+	}
+	confirm(t, s, subscription.ConfirmToken)
+
+	subscribe(t, s, "192.0.2.1", `{"email": "ops@example.com", "components": ["b"]}`)
+	if len(s.subscriberStore.subscribers) != 1 || !subscription.Confirmed || len(subscription.Components) != 1 || subscription.Components[0] != "a" {
+		t.Fatalf("expected the confirmed subscription to be kept; got %+v", subscription)
+	}
+	confirm(t, s, subscription.ConfirmToken)
+	if len(subscription.Components) != 1 || subscription.Components[0] != "b" || subscription.PendingComponents != nil || subscription.ConfirmToken != "" {
+		t.Errorf("expected the new components once confirmed; got %+v", subscription)
+	}
+	if len(s.subscriberStore.queue) != 2 {
+		t.Errorf("expected 2 confirmation emails; got %d", len(s.subscriberStore.queue))
+	}
+}
+
+func TestSubscribingIsThrottled(t *testing.T) {
+	s := subscriptionServer()
+
+	if code := subscribe(t, s, "192.0.2.1", `{"email": "ops@example.com", "components": ["c"]}`); code != http.StatusBadRequest {
+		t.Errorf("expected unknown components to be rejected; got %d", code)
+	}
+	for i := 0; i < subscribeRateLimit-1; i++ {
+		if code := subscribe(t, s, "192.0.2.1", `{"email": "ops@example.com", "components": ["a"]}`); code != 202 {
+			t.Fatalf("expected the request to be accepted; got %d", code)
+		}
+	}
+	if len(s.subscriberStore.queue) != 1 {
+		t.Errorf("expected a single confirmation email while the confirmation is pending; got %d", len(s.subscriberStore.queue))
+	}
+	if code := subscribe(t, s, "192.0.2.1", `{"email": "other@example.com"}`); code != 202 {
+		t.Fatalf("expected the request to be accepted; got %d", code)
+	}
+	if code := subscribe(t, s, "192.0.2.1", `{"email": "third@example.com"}`); code != http.StatusTooManyRequests {
+		t.Errorf("expected the client to be rate limited; got %d", code)
+	}
+	if code := subscribe(t, s, "192.0.2.2", `{"email": "third@example.com"}`); code != 202 {
+		t.Errorf("expected other clients not to be rate limited; got %d", code)
+	}
+	if len(s.subscriberStore.queue) != 3 {
+		t.Errorf("expected 3 confirmation emails; got %d", len(s.subscriberStore.queue))
+	}
+
+	for _, subscription := range s.subscriberStore.subscribers {
+		subscription.ConfirmSentAt = subscription.ConfirmSentAt.Add(-confirmResendInterval)
+	}
+	if code := subscribe(t, s, "192.0.2.2", `{"email": "ops@example.com"}`); code != 202 || len(s.subscriberStore.queue) != 4 {
+		t.Errorf("expected the confirmation email to be sent again once the pending one is old; got %d with %d emails", code, len(s.subscriberStore.queue))
+	}
+}
+
+// smtpSink accepts a single email on a local SMTP server and sends its recipient and content.
+func smtpSink(t *testing.T) (string, <-chan [2]string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	received := make(chan [2]string, 1)
+	go func() {
+		connection, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer connection.Close()
+		reader := bufio.NewReader(connection)
+		reply := func(line string) {
+			connection.Write([]byte(line + "\r\n"))
+		}
+		reply("220 localhost")
+		recipient, content := "", ""
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"), strings.HasPrefix(command, "MAIL FROM"):
+				reply("250 OK")
+			case strings.HasPrefix(command, "RCPT TO"):
+				recipient = strings.Trim(strings.TrimSpace(line)[len("RCPT TO:"):], "<>")
+				reply("250 OK")
+			case command == "DATA":
+				reply("354 go ahead")
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					content += line
+				}
+				received <- [2]string{recipient, content}
+				reply("250 OK")
+			case command == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("502 not implemented")
+			}
+		}
+	}()
+	return listener.Addr().String(), received
+}
+
+func TestSendEmail(t *testing.T) {
+	addr, received := smtpSink(t)
+	s := &ServerImplementation{
+		Email: EmailConfig{SMTPAddr: addr, From: "Status <status@example.com>"},
+	}
+	err := s.sendEmail(email{
+		To:             "ops@example.com",
+		Subject:        "Störung: API",
+		Body:           "Line one\nLine two",
+		UnsubscribeURL: "https://status.example.com/subscribers/unsubscribe?token=t",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := <-received
+	if message[0] != "ops@example.com" {
+		t.Errorf("expected the email to be sent to the subscriber; got %s", message[0])
+	}
+	for _, expected := range []string{
+		"From: Status <status@example.com>\r\n",
+		"To: ops@example.com\r\n",
+		"Subject: =?UTF-8?q?St=C3=B6rung:_API?=\r\n",
+		"List-Unsubscribe: <https://status.example.com/subscribers/unsubscribe?token=t>\r\n",
+		"List-Unsubscribe-Post: List-Unsubscribe=One-Click\r\n",
+		"@example.com>\r\n",
+		"\r\n\r\nLine one\r\nLine two",
+	} {
+		if !strings.Contains(message[1], expected) {
+			t.Errorf("expected the email to contain %q; got %q", expected, message[1])
+		}
+	}
+}
+
+func TestSubscribingSendsConfirmationEmail(t *testing.T) {
+	addr, received := smtpSink(t)
+	s := subscriptionServer()
+	s.subscriberStore.queue = nil
+	s.Email = EmailConfig{SMTPAddr: addr, From: "status@example.com"}
+	s.PublicURL = "https://status.example.com/"
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.RunEmails(ctx, echo.New().Logger)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for subscribe(t, s, "192.0.2.1", `{"email": "ops@example.com", "components": ["a"]}`) == http.StatusNotImplemented {
+		if time.Now().After(deadline) {
+			t.Fatal("email subscriptions were not enabled")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	select {
+	case message := <-received:
+		var token string
+		s.subscriberStore.mutex.Lock()
+		for _, subscription := range s.subscriberStore.subscribers {
+			token = subscription.ConfirmToken
+		}
+		s.subscriberStore.mutex.Unlock()
+		if message[0] != "ops@example.com" || !strings.Contains(message[1], "https://status.example.com/subscribers/confirm?token="+token) {
+			t.Errorf("expected a confirmation email with the confirmation link; got %q", message)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a confirmation email")
+	}
+}
+
+func TestSubscriberLinksOnlyShowForms(t *testing.T) {
+	s := &ServerImplementation{TimeZone: time.UTC}
+	if err := s.LoadPageTemplates(); err != nil {
+		t.Fatal(err)
+	}
+	s.subscriberStore.subscribers = map[string]*subscriber{
+		"id": {ConfirmToken: "confirm", UnsubscribeToken: "unsubscribe"},
+	}
+	e := echo.New()
+	serve := func(method string, target string, form url.Values) *httptest.ResponseRecorder {
+		var body io.Reader
+		if form != nil {
+			body = strings.NewReader(form.Encode())
+		}
+		request := httptest.NewRequest(method, target, body)
+		if form != nil {
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+		}
+		recorder := httptest.NewRecorder()
+		ctx := e.NewContext(request, recorder)
+		var err error
+		switch {
+		case method == http.MethodGet && strings.HasPrefix(target, "/subscribers/confirm"):
+			err = s.ShowSubscriberConfirmation(ctx, api.ShowSubscriberConfirmationParams{Token: ctx.QueryParam("token")})
+		case method == http.MethodGet:
+			err = s.ShowSubscriberUnsubscription(ctx, api.ShowSubscriberUnsubscriptionParams{Token: ctx.QueryParam("token")})
+		case strings.HasPrefix(target, "/subscribers/confirm"):
+			err = s.ConfirmSubscriber(ctx)
+		default:
+			params := api.UnsubscribeSubscriberParams{}
+			if token := ctx.QueryParam("token"); token != "" {
+				params.Token = &token
+			}
+			err = s.UnsubscribeSubscriber(ctx, params)
+		}
+		if err != nil {
+			e.HTTPErrorHandler(err, ctx)
+		}
+		return recorder
+	}
+	subscriber := s.subscriberStore.subscribers["id"]
+
+	page := serve(http.MethodGet, "/subscribers/confirm?token=confirm", nil)
+	if page.Code != 200 || !strings.Contains(page.Body.String(), `<form method="post" action="/subscribers/confirm">`) || !strings.Contains(page.Body.String(), `value="confirm"`) {
+		t.Errorf("expected a confirmation form; got %d %s", page.Code, page.Body.String())
+	}
+	if subscriber.Confirmed {
+		t.Errorf("expected following the link not to confirm")
+	}
+	if response := serve(http.MethodGet, "/subscribers/confirm?token=unknown", nil); response.Code != 404 {
+		t.Errorf("expected unknown tokens to be rejected; got %d", response.Code)
+	}
+	if response := serve(http.MethodPost, "/subscribers/confirm", url.Values{"token": {"confirm"}}); response.Code != 200 || !subscriber.Confirmed {
+		t.Errorf("expected posting the form to confirm; got %d", response.Code)
+	}
+	if response := serve(http.MethodPost, "/subscribers/confirm", url.Values{"token": {"confirm"}}); response.Code != 404 {
+		t.Errorf("expected the confirmation token to work only once; got %d", response.Code)
+	}
+
+	if response := serve(http.MethodGet, "/subscribers/unsubscribe?token=unsubscribe", nil); response.Code != 200 || len(s.subscriberStore.subscribers) != 1 {
+		t.Errorf("expected following the link to only show a form; got %d", response.Code)
+	}
+	// One-click unsubscribing posts to the link itself
+	if response := serve(http.MethodPost, "/subscribers/unsubscribe?token=unsubscribe", url.Values{"List-Unsubscribe": {"One-Click"}}); response.Code != 200 || len(s.subscriberStore.subscribers) != 0 {
+		t.Errorf("expected one-click unsubscribing to cancel the subscription; got %d", response.Code)
+	}
+}
@@ -0,0 +1,225 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/joshmue/scs-status-page-openapi/pkg/api"
+	"github.com/labstack/echo/v4"
+)
+
+// Types of events derived from successive snapshots.
+const (
+	EventIncidentCreated        = "incident.created"
+	EventIncidentUpdated        = "incident.updated"
+	EventIncidentResolved       = "incident.resolved"
+	EventComponentStatusChanged = "component.status_changed"
+	// EventReset tells clients that events were missed and they have to refetch everything.
+	EventReset = "reset"
+)
+
+const (
+	// eventLogSize is the number of past events kept for clients resuming their stream.
+	eventLogSize = 1000
+	// subscriberBufferSize is the number of events buffered per subscriber.
+	// Subscribers falling further behind are disconnected.
+	subscriberBufferSize = 100
+	// eventKeepAliveInterval is the interval of comments keeping idle streams open.
+	eventKeepAliveInterval = 30 * time.Second
+)
+
+// Event is a change of an incident or of the status of a component.
+type Event struct {
+	Id            uint64        `json:"id"`
+	Type          string        `json:"type"`
+	Time          time.Time     `json:"time"`
+	Incident      *api.Incident `json:"incident,omitempty"`
+	PreviousPhase string        `json:"previousPhase,omitempty"`
+	// PhaseChanged is set for incidents whose phase changed, including from no phase to a phase
+	PhaseChanged   bool   `json:"phaseChanged,omitempty"`
+	ComponentId    string `json:"componentId,omitempty"`
+	Status         string `json:"status,omitempty"`
+	PreviousStatus string `json:"previousStatus,omitempty"`
+}
+
+// eventLog keeps recent events and distributes new ones to subscribers.
+type eventLog struct {
+	mutex  sync.Mutex
+	events []Event
+	// firstId is the last ID before this process published any events
+	firstId     uint64
+	lastId      uint64
+	subscribers map[chan Event]struct{}
+	// listeners are called for every event after it is stored, without holding the mutex
+	listeners []func(Event)
+	// publishMutex serializes publishing, so listeners receive events in order
+	publishMutex sync.Mutex
+}
+
+// init sets up the log on first use. The caller must hold the mutex.
+func (l *eventLog) init() {
+	if l.subscribers != nil {
+		return
+	}
+	l.subscribers = map[chan Event]struct{}{}
+	// IDs keep increasing across restarts, so IDs of previous processes can be detected
+	l.firstId = uint64(time.Now().UnixMilli())
+	l.lastId = l.firstId
+}
+
+// publish assigns IDs to the events, stores them and sends them to all subscribers and listeners.
+// Subscribers which cannot keep up are unsubscribed by closing their channel.
+func (l *eventLog) publish(events []Event) {
+	l.publishMutex.Lock()
+	defer l.publishMutex.Unlock()
+	l.mutex.Lock()
+	l.init()
+	now := time.Now()
+	published := make([]Event, 0, len(events))
+	for _, event := range events {
+		l.lastId++
+		event.Id = l.lastId
+		event.Time = now
+		l.events = append(l.events, event)
+		published = append(published, event)
+		for subscriber := range l.subscribers {
+			select {
+			case subscriber <- event:
+			default:
+				delete(l.subscribers, subscriber)
+				close(subscriber)
+			}
+		}
+	}
+	if len(l.events) > eventLogSize {
+		l.events = append([]Event{}, l.events[len(l.events)-eventLogSize:]...)
+	}
+	listeners := append([]func(Event){}, l.listeners...)
+	l.mutex.Unlock()
+
+	// Listeners may take locks of their own, which must not be held while waiting for the log
+	for _, event := range published {
+		for _, listener := range listeners {
+			listener(event)
+		}
+	}
+}
+
+// subscribe returns a channel of future events along with all stored events after lastEventId.
+// If events after lastEventId are no longer stored, complete is false.
+func (l *eventLog) subscribe(lastEventId *uint64) (subscriber chan Event, missed []Event, complete bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.init()
+	subscriber = make(chan Event, subscriberBufferSize)
+	l.subscribers[subscriber] = struct{}{}
+	if lastEventId == nil {
+		return subscriber, nil, true
+	}
+	if *lastEventId < l.firstId {
+		return subscriber, nil, false
+	}
+	if *lastEventId >= l.lastId {
+		return subscriber, nil, true
+	}
+	for _, event := range l.events {
+		if event.Id > *lastEventId {
+			missed = append(missed, event)
+		}
+	}
+	complete = len(missed) > 0 && missed[0].Id == *lastEventId+1
+	return subscriber, missed, complete
+}
+
+// listen registers a function to be called for every future event.
+func (l *eventLog) listen(listener func(Event)) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.listeners = append(l.listeners, listener)
+}
+
+func (l *eventLog) unsubscribe(subscriber chan Event) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if _, ok := l.subscribers[subscriber]; ok {
+		delete(l.subscribers, subscriber)
+		close(subscriber)
+	}
+}
+
+// RegisterEventHandlers adds a Server-Sent Events stream of incident and component status changes.
+func (s *ServerImplementation) RegisterEventHandlers(router api.EchoRouter) {
+	router.GET("/events", s.GetEvents)
+}
+
+// GetEvents streams events as Server-Sent Events. Clients resume their stream by
+// sending the ID of the last event received as "Last-Event-ID" header.
+func (s *ServerImplementation) GetEvents(ctx echo.Context) error {
+	var lastEventId *uint64
+	if header := ctx.Request().Header.Get("Last-Event-ID"); header != "" {
+		id, err := strconv.ParseUint(header, 10, 64)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid Last-Event-ID")
+		}
+		lastEventId = &id
+	}
+	subscriber, missed, complete := s.events.subscribe(lastEventId)
+	defer s.events.unsubscribe(subscriber)
+
+	response := ctx.Response()
+	response.Header().Set(echo.HeaderContentType, "text/event-stream")
+	response.Header().Set(echo.HeaderCacheControl, "no-cache")
+	response.Header().Set(echo.HeaderConnection, "keep-alive")
+	response.WriteHeader(200)
+	if !complete {
+		if err := writeEvent(response, Event{Type: EventReset, Time: time.Now()}); err != nil {
+			return nil
+		}
+	}
+	for _, event := range missed {
+		if err := writeEvent(response, event); err != nil {
+			return nil
+		}
+	}
+	response.Flush()
+
+	keepAlive := time.NewTicker(eventKeepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-ctx.Request().Context().Done():
+			return nil
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(response, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+		case event, ok := <-subscriber:
+			if !ok {
+				// Too slow; the client has to reconnect and resume
+				return nil
+			}
+			if err := writeEvent(response, event); err != nil {
+				return nil
+			}
+		}
+		response.Flush()
+	}
+}
+
+func writeEvent(response *echo.Response, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if event.Id != 0 {
+		if _, err := fmt.Fprintf(response, "id: %d\n", event.Id); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(response, "event: %s\ndata: %s\n\n", event.Type, data)
+	return err
+}
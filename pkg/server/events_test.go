@@ -0,0 +1,125 @@
+package server
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestEventLogResume(t *testing.T) {
+	var l eventLog
+	l.publish([]Event{{Type: EventIncidentCreated}, {Type: EventIncidentUpdated}, {Type: EventIncidentResolved}})
+	if len(l.events) != 3 || l.events[0].Id != l.firstId+1 || l.events[1].Id != l.events[0].Id+1 || l.events[2].Id != l.lastId {
+		t.Fatalf("expected consecutive IDs after %d; got %+v", l.firstId, l.events)
+	}
+	first := l.events[0].Id
+	previousProcess := l.firstId - 1
+	for _, test := range []struct {
+		name        string
+		lastEventId *uint64
+		missed      []string
+		complete    bool
+	}{
+		{"new stream", nil, nil, true},
+		{"resuming", &first, []string{EventIncidentUpdated, EventIncidentResolved}, true},
+		{"up to date", &l.lastId, nil, true},
+		{"previous process", &previousProcess, nil, false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			subscriber, missed, complete := l.subscribe(test.lastEventId)
+			defer l.unsubscribe(subscriber)
+			types := []string{}
+			for _, event := range missed {
+				types = append(types, event.Type)
+			}
+			if strings.Join(types, ",") != strings.Join(test.missed, ",") || complete != test.complete {
+				t.Errorf("expected %q, complete %t; got %q, complete %t", test.missed, test.complete, types, complete)
+			}
+		})
+	}
+}
+
+func TestEventListenersRunWithoutLock(t *testing.T) {
+	var l eventLog
+	received := []uint64{}
+	l.listen(func(event Event) {
+		// Using the log from a listener deadlocked while listeners were called with the mutex held
+		subscriber, _, _ := l.subscribe(nil)
+		l.unsubscribe(subscriber)
+		received = append(received, event.Id)
+	})
+	done := make(chan struct{})
+	go func() {
+		l.publish([]Event{{Type: EventIncidentCreated}, {Type: EventIncidentUpdated}})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("publishing did not finish")
+	}
+	if len(received) != 2 || received[0] != l.lastId-1 || received[1] != l.lastId {
+		t.Errorf("expected the listener to receive both events in order; got %v", received)
+	}
+}
+
+func TestGetEventsResumes(t *testing.T) {
+	s := &ServerImplementation{}
+	s.events.publish([]Event{{Type: EventIncidentCreated}, {Type: EventIncidentResolved}})
+	e := echo.New()
+	s.RegisterEventHandlers(e)
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	stream := func(lastEventId string) (*http.Response, *bufio.Reader) {
+		request, err := http.NewRequest(http.MethodGet, server.URL+"/events", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		request.Header.Set("Last-Event-ID", lastEventId)
+		response, err := server.Client().Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return response, bufio.NewReader(response.Body)
+	}
+	readEvent := func(reader *bufio.Reader) []string {
+		lines := []string{}
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatal(err)
+			}
+			if line == "\n" {
+				return lines
+			}
+			lines = append(lines, strings.TrimSuffix(line, "\n"))
+		}
+	}
+
+	response, reader := stream(strconv.FormatUint(s.events.lastId-1, 10))
+	lines := readEvent(reader)
+	response.Body.Close()
+	if len(lines) != 3 || lines[0] != "id: "+strconv.FormatUint(s.events.lastId, 10) || lines[1] != "event: "+EventIncidentResolved {
+		t.Errorf("expected the missed event; got %q", lines)
+	}
+
+	response, reader = stream("1")
+	lines = readEvent(reader)
+	response.Body.Close()
+	if len(lines) != 2 || lines[0] != "event: "+EventReset {
+		t.Errorf("expected a reset for events of a previous process; got %q", lines)
+	}
+
+	response, _ = stream("invalid")
+	response.Body.Close()
+	if response.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected an invalid Last-Event-ID to be rejected; got %d", response.StatusCode)
+	}
+}
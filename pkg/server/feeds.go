@@ -0,0 +1,245 @@
+package server
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joshmue/scs-status-page-openapi/pkg/api"
+	"github.com/labstack/echo/v4"
+)
+
+// feedLength limits the number of entries in a feed.
+const feedLength = 50
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	SelfLink      atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Description string  `xml:"description"`
+	Guid        rssGuid `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+type rssGuid struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	Id      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title   string   `xml:"title"`
+	Id      string   `xml:"id"`
+	Updated string   `xml:"updated"`
+	Link    atomLink `xml:"link"`
+	Summary string   `xml:"summary"`
+	Author  struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+}
+
+// feedEntry is the format-independent content of a feed entry.
+type feedEntry struct {
+	Id      string
+	Title   string
+	Link    string
+	Summary string
+	Updated time.Time
+}
+
+// RegisterFeedHandlers adds RSS and Atom feeds of incidents, both for all and per component.
+func (s *ServerImplementation) RegisterFeedHandlers(router api.EchoRouter) {
+	router.GET("/incidents.rss", s.GetIncidentsRSS)
+	router.GET("/incidents.atom", s.GetIncidentsAtom)
+	router.GET("/components/:componentId/incidents.rss", s.GetIncidentsRSS)
+	router.GET("/components/:componentId/incidents.atom", s.GetIncidentsAtom)
+}
+
+// incidentGuid returns a stable identifier of an incident for use in feeds.
+func incidentGuid(incidentId string) string {
+	return "urn:scs-status-page:incident:" + incidentId
+}
+
+// publicLink returns the absolute URL of path on the server. Links do not depend on the
+// Host header of requests, so feeds cannot be made to link to other sites.
+func (s *ServerImplementation) publicLink(path string) string {
+	return strings.TrimSuffix(s.PublicURL, "/") + path
+}
+
+// feedEntries returns feed entries for the incidents affecting the component
+// given by the "componentId" path parameter, or all incidents if it is not set.
+// The title describes the feed.
+func (s *ServerImplementation) feedEntries(ctx echo.Context) (title string, entries []feedEntry, err error) {
+	components, err := s.Components(ctx.Request().Context())
+	if err != nil {
+		return "", nil, upstreamError(ctx, err)
+	}
+	incidents, err := s.Incidents(ctx.Request().Context(), ctx.Logger())
+	if err != nil {
+		return "", nil, upstreamError(ctx, err)
+	}
+	displayNames := map[string]string{}
+	for _, component := range components {
+		displayNames[component.Id] = component.DisplayName
+	}
+	title = "Incidents"
+	componentId := ctx.Param("componentId")
+	if componentId != "" {
+		displayName, ok := displayNames[componentId]
+		if !ok {
+			return "", nil, echo.NewHTTPError(404)
+		}
+		title = fmt.Sprintf("Incidents affecting %s", displayName)
+	}
+
+	for _, incident := range incidents {
+		if componentId != "" && !contains(incident.Affects, componentId) {
+			continue
+		}
+		affected := []string{}
+		for _, id := range incident.Affects {
+			affected = append(affected, displayNames[id])
+		}
+		summary := fmt.Sprintf("Phase: %s\nImpact type: %s\nAffected components: %s", incident.Phase, incident.ImpactType, strings.Join(affected, ", "))
+		if incident.BeganAt != nil {
+			summary += "\nBegan at: " + incident.BeganAt.Format(time.RFC3339)
+		}
+		if incident.EndedAt != nil {
+			summary += "\nEnded at: " + incident.EndedAt.Format(time.RFC3339)
+		}
+		entries = append(entries, feedEntry{
+			Id:      incident.Id,
+			Title:   fmt.Sprintf("[%s] %s", incident.Phase, incident.Title),
+			Link:    s.publicLink("/status/incidents/" + incident.Id),
+			Summary: summary,
+			Updated: incidentUpdatedAt(incident),
+		})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Updated.After(entries[j].Updated)
+	})
+	if len(entries) > feedLength {
+		entries = entries[:feedLength]
+	}
+	return title, entries, nil
+}
+
+// incidentUpdatedAt returns when an incident last changed its phase, falling back to its beginning.
+func incidentUpdatedAt(incident api.Incident) time.Time {
+	switch {
+	case incident.PhaseChangedAt != nil:
+		return *incident.PhaseChangedAt
+	case incident.BeganAt != nil:
+		return *incident.BeganAt
+	}
+	return time.Time{}
+}
+
+func feedUpdatedAt(entries []feedEntry) time.Time {
+	if len(entries) == 0 {
+		return time.Time{}
+	}
+	return entries[0].Updated
+}
+
+func (s *ServerImplementation) GetIncidentsRSS(ctx echo.Context) error {
+	title, entries, err := s.feedEntries(ctx)
+	if err != nil {
+		return err
+	}
+	feed := rssFeed{
+		Version: "2.0",
+		Atom:    "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:         title,
+			Link:          s.publicLink("/"),
+			Description:   title,
+			LastBuildDate: feedUpdatedAt(entries).Format(time.RFC1123Z),
+			SelfLink: atomLink{
+				Href: s.publicLink(ctx.Request().URL.Path),
+				Rel:  "self",
+				Type: "application/rss+xml",
+			},
+			Items: []rssItem{},
+		},
+	}
+	for _, entry := range entries {
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       entry.Title,
+			Link:        entry.Link,
+			Description: entry.Summary,
+			Guid:        rssGuid{Value: incidentGuid(entry.Id)},
+			PubDate:     entry.Updated.Format(time.RFC1123Z),
+		})
+	}
+	return renderXML(ctx, "application/rss+xml; charset=UTF-8", feed)
+}
+
+func (s *ServerImplementation) GetIncidentsAtom(ctx echo.Context) error {
+	title, entries, err := s.feedEntries(ctx)
+	if err != nil {
+		return err
+	}
+	self := s.publicLink(ctx.Request().URL.Path)
+	feed := atomFeed{
+		Title:   title,
+		Id:      self,
+		Updated: feedUpdatedAt(entries).Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: self, Rel: "self", Type: "application/atom+xml"},
+		},
+		Entries: []atomEntry{},
+	}
+	for _, entry := range entries {
+		converted := atomEntry{
+			Title:   entry.Title,
+			Id:      incidentGuid(entry.Id),
+			Updated: entry.Updated.Format(time.RFC3339),
+			Link:    atomLink{Href: entry.Link},
+			Summary: entry.Summary,
+		}
+		converted.Author.Name = title
+		feed.Entries = append(feed.Entries, converted)
+	}
+	return renderXML(ctx, "application/atom+xml; charset=UTF-8", feed)
+}
+
+func renderXML(ctx echo.Context, contentType string, v interface{}) error {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(500)
+	}
+	return ctx.Blob(200, contentType, append([]byte(xml.Header), body...))
+}
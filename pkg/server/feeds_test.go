@@ -0,0 +1,49 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestFeedsLinkToPublicURL(t *testing.T) {
+	s := &ServerImplementation{
+		GithubV4Client: fakeGithub(t, func(query string, variables map[string]interface{}) string {
+			if strings.Contains(query, "repositories(") {
+				return `{"data":{"node":{"repositories":{"nodes":[],"pageInfo":{"hasNextPage":false}}}}}`
+			}
+			return `{"data":{"node":{"items":{"nodes":[{"id":"1"}],"pageInfo":{"hasNextPage":false}}}}}`
+		}),
+		ProjectID: "project",
+		Fields:    DefaultFieldNames,
+		PublicURL: "https://status.example.com/",
+	}
+	for path, handler := range map[string]echo.HandlerFunc{
+		"/incidents.rss":  s.GetIncidentsRSS,
+		"/incidents.atom": s.GetIncidentsAtom,
+	} {
+		t.Run(path, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, path, nil)
+			request.Host = "attacker.example.com"
+			recorder := httptest.NewRecorder()
+			if err := handler(echo.New().NewContext(request, recorder)); err != nil {
+				t.Fatal(err)
+			}
+			body := recorder.Body.String()
+			if strings.Contains(body, "attacker.example.com") {
+				t.Errorf("expected links not to depend on the Host header; got %s", body)
+			}
+			for _, expected := range []string{
+				`href="https://status.example.com` + path + `"`,
+				"https://status.example.com/status/incidents/1",
+			} {
+				if !strings.Contains(body, expected) {
+					t.Errorf("expected the feed to contain %q; got %s", expected, body)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/shurcooL/githubv4"
+)
+
+// FieldNames maps the semantic fields of incidents to the names of project fields.
+type FieldNames struct {
+	// Phase is a single select field, whose options are the phases in order
+	Phase string
+	// ImpactType is a single select field, whose options are the impact types
+	ImpactType string
+	// BeganAt and EndedAt are text fields holding timestamps or date fields
+	BeganAt string
+	EndedAt string
+	// BeganAtTime and EndedAtTime optionally name text fields holding the time of day,
+	// along with BeganAt and EndedAt only holding the date. Without them, a date in
+	// BeganAt refers to the start of the day and one in EndedAt to its end, the next midnight.
+	BeganAtTime string
+	EndedAtTime string
+	// Labels is the built-in field listing the labels of the issue, which reference components
+	Labels string
+}
+
+// DefaultFieldNames are the field names of an English-language project.
+var DefaultFieldNames = FieldNames{
+	Phase:      "Status",
+	ImpactType: "Impact Type",
+	BeganAt:    "Began At",
+	EndedAt:    "Ended At",
+	Labels:     "Labels",
+}
+
+// DefaultComponentPrefix is the prefix of labels marking components.
+const DefaultComponentPrefix = "component:"
+
+// withFieldNames adds the field names as variables of queries for project items.
+func (s *ServerImplementation) withFieldNames(variables map[string]interface{}) map[string]interface{} {
+	variables["phasefield"] = githubv4.String(s.Fields.Phase)
+	variables["impacttypefield"] = githubv4.String(s.Fields.ImpactType)
+	variables["beganatfield"] = githubv4.String(s.Fields.BeganAt)
+	variables["endedatfield"] = githubv4.String(s.Fields.EndedAt)
+	variables["beganattimefield"] = githubv4.String(s.Fields.BeganAtTime)
+	variables["endedattimefield"] = githubv4.String(s.Fields.EndedAtTime)
+	variables["withbeganattime"] = githubv4.Boolean(s.Fields.BeganAtTime != "")
+	variables["withendedattime"] = githubv4.Boolean(s.Fields.EndedAtTime != "")
+	variables["labelsfield"] = githubv4.String(s.Fields.Labels)
+	return variables
+}
+
+// isComponentLabel reports whether a label marks a component.
+func (s *ServerImplementation) isComponentLabel(name string) bool {
+	return strings.HasPrefix(name, s.ComponentPrefix)
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestIncidentsUseConfiguredFieldNames(t *testing.T) {
+	fields := FieldNames{
+		Phase:       "Zustand",
+		ImpactType:  "Auswirkung",
+		BeganAt:     "Beginn",
+		EndedAt:     "Ende",
+		BeganAtTime: "Beginn Uhrzeit",
+		Labels:      "Etiketten",
+	}
+	s := &ServerImplementation{
+		GithubV4Client: fakeGithub(t, func(query string, variables map[string]interface{}) string {
+			for variable, expected := range map[string]interface{}{
+				"phasefield":       "Zustand",
+				"impacttypefield":  "Auswirkung",
+				"beganatfield":     "Beginn",
+				"endedatfield":     "Ende",
+				"beganattimefield": "Beginn Uhrzeit",
+				"withbeganattime":  true,
+				"withendedattime":  false,
+				"labelsfield":      "Etiketten",
+			} {
+				if variables[variable] != expected {
+					t.Errorf("expected %s to be %v; got %v", variable, expected, variables[variable])
+				}
+			}
+			return `{"data":{"node":{"items":{"nodes":[
+				{"id":"1","phase":{"name":"Untersuchung"},"beganat":{"date":"2024-01-02"},"beganattime":{"text":"03:04"}}
+			],"pageInfo":{"hasNextPage":false}}}}}`
+		}),
+		ProjectID:         "project",
+		Fields:            fields,
+		TimestampTimeZone: time.UTC,
+	}
+	incidents, err := s.Incidents(context.Background(), echo.New().Logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(incidents) != 1 || incidents[0].Phase != "Untersuchung" || incidents[0].BeganAt == nil || !incidents[0].BeganAt.Equal(time.Date(2024, 1, 2, 3, 4, 0, 0, time.UTC)) {
+		t.Errorf("expected the incident with its fields; got %+v", incidents)
+	}
+}
+
+func TestIsComponentLabel(t *testing.T) {
+	s := &ServerImplementation{ComponentPrefix: "komponente:"}
+	if !s.isComponentLabel("komponente:API") || s.isComponentLabel("component:API") {
+		t.Error("expected labels with the configured prefix to be components")
+	}
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/joshmue/scs-status-page-openapi/pkg/api"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// diagnosticsTimeout bounds how long checking the project configuration may take.
+	diagnosticsTimeout = 10 * time.Second
+	// readinessCheckInterval is how long readiness probes reuse the last check of the project configuration.
+	readinessCheckInterval = time.Minute
+)
+
+// diagnosisCache keeps the last check of the project configuration for readiness probes.
+type diagnosisCache struct {
+	mutex  sync.Mutex
+	latest *api.Diagnostics
+}
+
+// RegisterHealthHandlers adds liveness and readiness endpoints.
+func (s *ServerImplementation) RegisterHealthHandlers(router api.EchoRouter) {
+	router.GET("/healthz", s.GetHealth)
+	router.GET("/readyz", s.GetReadiness)
+}
+
+// diagnose checks the project configuration, reporting a failure to query GitHub as a problem.
+func (s *ServerImplementation) diagnose(ctx context.Context) api.Diagnostics {
+	ctx, cancel := context.WithTimeout(ctx, diagnosticsTimeout)
+	defer cancel()
+	diagnostics := api.Diagnostics{
+		CheckedAt: time.Now(),
+		ProjectId: &s.ProjectID,
+	}
+	problems, err := s.ProjectConfigurationProblems(ctx)
+	if err != nil {
+		problems = []api.ConfigurationProblem{{
+			Check:   "github",
+			Message: err.Error(),
+		}}
+	}
+	diagnostics.Problems = problems
+	diagnostics.Ok = len(problems) == 0
+	return diagnostics
+}
+
+// GetHealth reports that the process is up.
+func (s *ServerImplementation) GetHealth(ctx echo.Context) error {
+	return ctx.JSON(200, map[string]string{"status": "ok"})
+}
+
+// GetReadiness reports whether GitHub is reachable and the project is configured as expected.
+// The project configuration is checked at most once per readinessCheckInterval, so frequent
+// probes do not use up the GitHub rate limit.
+func (s *ServerImplementation) GetReadiness(ctx echo.Context) error {
+	s.diagnosisCache.mutex.Lock()
+	if s.diagnosisCache.latest == nil || time.Since(s.diagnosisCache.latest.CheckedAt) >= readinessCheckInterval {
+		diagnostics := s.diagnose(ctx.Request().Context())
+		s.diagnosisCache.latest = &diagnostics
+	}
+	diagnostics := *s.diagnosisCache.latest
+	s.diagnosisCache.mutex.Unlock()
+	if !diagnostics.Ok {
+		for _, problem := range diagnostics.Problems {
+			ctx.Logger().Warnf("Not ready: %s", problem.Message)
+		}
+		return ctx.JSON(http.StatusServiceUnavailable, map[string]string{"status": "unavailable"})
+	}
+	return ctx.JSON(200, map[string]string{"status": "ok"})
+}
+
+func (s *ServerImplementation) GetDiagnostics(ctx echo.Context) error {
+	if err := s.authorize(ctx); err != nil {
+		return err
+	}
+	return ctx.JSON(200, s.diagnose(ctx.Request().Context()))
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestReadinessReusesRecentCheck(t *testing.T) {
+	requests := 0
+	s := &ServerImplementation{
+		GithubV4Client: fakeGithub(t, func(query string, variables map[string]interface{}) string {
+			requests++
+			return `{"data":{}}`
+		}),
+		ProjectID:  "project",
+		Fields:     DefaultFieldNames,
+		AdminToken: "secret",
+	}
+	e := echo.New()
+	s.RegisterHealthHandlers(e)
+	e.GET("/diagnostics", s.GetDiagnostics)
+	serve := func(path string, token string) int {
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			request.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		}
+		recorder := httptest.NewRecorder()
+		e.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	if code := serve("/readyz", ""); code != http.StatusServiceUnavailable {
+		t.Errorf("expected the problems of the project to be reported; got %d", code)
+	}
+	checked := requests
+	if checked == 0 {
+		t.Fatal("expected the project configuration to be checked")
+	}
+	if code := serve("/readyz", ""); code != http.StatusServiceUnavailable || requests != checked {
+		t.Errorf("expected the recent check to be reused; got %d after %d requests", code, requests-checked)
+	}
+
+	if code := serve("/diagnostics", ""); code != http.StatusUnauthorized || requests != checked {
+		t.Errorf("expected diagnostics to require the admin token; got %d after %d requests", code, requests-checked)
+	}
+	if code := serve("/diagnostics", "secret"); code != 200 || requests == checked {
+		t.Errorf("expected diagnostics to check the project again; got %d after %d requests", code, requests-checked)
+	}
+}
@@ -0,0 +1,208 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joshmue/scs-status-page-openapi/pkg/api"
+	"github.com/labstack/echo/v4"
+)
+
+// heartbeatCheckInterval is how often overdue heartbeats are looked for.
+const heartbeatCheckInterval = 10 * time.Second
+
+// Heartbeat expects a service to check in on behalf of a component at least once per interval.
+type Heartbeat struct {
+	Name      string `json:"name"`
+	Component api.Id `json:"component"`
+	// Token is the secret the service checks in with
+	Token      string        `json:"token"`
+	Interval   time.Duration `json:"-"`
+	ImpactType string        `json:"impactType"`
+}
+
+// LoadHeartbeats reads a JSON list of heartbeats from a file. Intervals are given as durations like "5m".
+func LoadHeartbeats(path string) ([]Heartbeat, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries []struct {
+		Heartbeat
+		Interval string `json:"interval"`
+	}
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return nil, err
+	}
+	heartbeats := []Heartbeat{}
+	names := map[string]bool{}
+	for _, entry := range entries {
+		heartbeat := entry.Heartbeat
+		if heartbeat.Name == "" || names[heartbeat.Name] {
+			return nil, fmt.Errorf(`heartbeat "%s": expected a unique name`, heartbeat.Name)
+		}
+		names[heartbeat.Name] = true
+		if heartbeat.Component == "" || heartbeat.Token == "" || heartbeat.ImpactType == "" {
+			return nil, fmt.Errorf(`heartbeat "%s": expected component, token and impact type`, heartbeat.Name)
+		}
+		if heartbeat.Interval, err = time.ParseDuration(entry.Interval); err != nil {
+			return nil, fmt.Errorf(`heartbeat "%s": %w`, heartbeat.Name, err)
+		}
+		if heartbeat.Interval <= 0 {
+			return nil, fmt.Errorf(`heartbeat "%s": expected positive interval`, heartbeat.Name)
+		}
+		heartbeats = append(heartbeats, heartbeat)
+	}
+	return heartbeats, nil
+}
+
+type heartbeatState struct {
+	lastSeenAt time.Time
+	incidentId string
+	// openedAt is when the incident was opened; heartbeats after it resolve the incident
+	openedAt time.Time
+}
+
+// heartbeatIncident is an incident opened by a heartbeat, as persisted across restarts.
+type heartbeatIncident struct {
+	IncidentId string    `json:"incidentId"`
+	OpenedAt   time.Time `json:"openedAt"`
+}
+
+// heartbeatStore holds the state of all heartbeats by name.
+type heartbeatStore struct {
+	mutex  sync.Mutex
+	states map[string]*heartbeatState
+}
+
+// saveHeartbeatIncidents persists the open incidents of heartbeats. The caller must hold the mutex.
+func (s *ServerImplementation) saveHeartbeatIncidents() error {
+	incidents := map[string]heartbeatIncident{}
+	for name, state := range s.heartbeatStore.states {
+		if state.incidentId != "" {
+			incidents[name] = heartbeatIncident{IncidentId: state.incidentId, OpenedAt: state.openedAt}
+		}
+	}
+	return saveState(s.HeartbeatStateFile, incidents)
+}
+
+// SendHeartbeat records a heartbeat authenticated by its token as bearer token, which keeps it out of access logs.
+func (s *ServerImplementation) SendHeartbeat(ctx echo.Context, componentId string) error {
+	now := time.Now()
+	token := strings.TrimPrefix(ctx.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+	s.heartbeatStore.mutex.Lock()
+	defer s.heartbeatStore.mutex.Unlock()
+	for _, heartbeat := range s.Heartbeats {
+		if heartbeat.Component != componentId || subtle.ConstantTimeCompare([]byte(heartbeat.Token), []byte(token)) != 1 {
+			continue
+		}
+		if state, ok := s.heartbeatStore.states[heartbeat.Name]; ok {
+			state.lastSeenAt = now
+		}
+		return ctx.NoContent(http.StatusNoContent)
+	}
+	return echo.NewHTTPError(http.StatusUnauthorized, "unknown heartbeat token")
+}
+
+// RunHeartbeats opens an incident whenever a heartbeat is overdue and resolves it
+// once heartbeats resume. Heartbeats are considered seen at startup, so services have
+// one interval to check in. Incidents still open from before a restart are resolved by
+// the next heartbeat. It blocks until ctx is done.
+func (s *ServerImplementation) RunHeartbeats(ctx context.Context, logger echo.Logger) error {
+	incidents := map[string]heartbeatIncident{}
+	if err := loadState(s.HeartbeatStateFile, &incidents); err != nil {
+		return err
+	}
+	s.heartbeatStore.mutex.Lock()
+	s.heartbeatStore.states = map[string]*heartbeatState{}
+	for _, heartbeat := range s.Heartbeats {
+		state := &heartbeatState{lastSeenAt: time.Now()}
+		if incident, ok := incidents[heartbeat.Name]; ok {
+			// Only heartbeats arriving after the restart resolve the incident
+			state.incidentId, state.openedAt, state.lastSeenAt = incident.IncidentId, incident.OpenedAt, incident.OpenedAt
+		}
+		s.heartbeatStore.states[heartbeat.Name] = state
+	}
+	s.heartbeatStore.mutex.Unlock()
+
+	ticker := time.NewTicker(heartbeatCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		for _, heartbeat := range s.Heartbeats {
+			s.checkHeartbeat(ctx, logger, heartbeat)
+		}
+	}
+}
+
+func (s *ServerImplementation) checkHeartbeat(ctx context.Context, logger echo.Logger, heartbeat Heartbeat) {
+	now := time.Now()
+	s.heartbeatStore.mutex.Lock()
+	state := *s.heartbeatStore.states[heartbeat.Name]
+	s.heartbeatStore.mutex.Unlock()
+
+	switch {
+	case state.incidentId == "" && now.Sub(state.lastSeenAt) > heartbeat.Interval:
+		logger.Infof(`Heartbeat "%s" is overdue, opening incident`, heartbeat.Name)
+		incidentId, err := s.openHeartbeatIncident(ctx, heartbeat, state.lastSeenAt)
+		if err != nil {
+			logger.Errorf(`Opening incident for heartbeat "%s" failed: %s`, heartbeat.Name, err)
+		}
+		if incidentId != "" {
+			s.setHeartbeatIncident(logger, heartbeat, incidentId, now)
+		}
+	case state.incidentId != "" && state.lastSeenAt.After(state.openedAt):
+		logger.Infof(`Heartbeat "%s" resumed, resolving incident "%s"`, heartbeat.Name, state.incidentId)
+		err := s.setItemTimestamp(ctx, state.incidentId, s.Fields.EndedAt, s.Fields.EndedAtTime, state.lastSeenAt, true)
+		if err == nil {
+			err = s.setItemFieldValue(ctx, state.incidentId, s.Fields.Phase, s.LastPhase)
+		}
+		if err != nil {
+			// Retried on the next check
+			logger.Errorf(`Resolving incident "%s" of heartbeat "%s" failed: %s`, state.incidentId, heartbeat.Name, err)
+			return
+		}
+		s.setHeartbeatIncident(logger, heartbeat, "", time.Time{})
+	}
+}
+
+func (s *ServerImplementation) setHeartbeatIncident(logger echo.Logger, heartbeat Heartbeat, incidentId string, openedAt time.Time) {
+	s.heartbeatStore.mutex.Lock()
+	defer s.heartbeatStore.mutex.Unlock()
+	state := s.heartbeatStore.states[heartbeat.Name]
+	state.incidentId = incidentId
+	state.openedAt = openedAt
+	if err := s.saveHeartbeatIncidents(); err != nil {
+		logger.Error(err)
+	}
+}
+
+func (s *ServerImplementation) openHeartbeatIncident(ctx context.Context, heartbeat Heartbeat, lastSeenAt time.Time) (string, error) {
+	phases, err := s.Phases(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(phases) == 0 {
+		return "", fmt.Errorf("could not determine initial phase")
+	}
+	beganAt := lastSeenAt.Add(heartbeat.Interval)
+	return s.createItem(ctx, newItem{
+		Title:      fmt.Sprintf(`No heartbeat from "%s"`, heartbeat.Name),
+		Body:       fmt.Sprintf("No heartbeat arrived within %s since %s.", heartbeat.Interval, lastSeenAt.Format(time.RFC3339)),
+		Affects:    []string{heartbeat.Component},
+		Phase:      phases[0],
+		ImpactType: heartbeat.ImpactType,
+		BeganAt:    &beganAt,
+	})
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestLoadHeartbeats(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		interval time.Duration
+		fails    bool
+	}{
+		{
+			name:     "interval",
+			content:  `[{"name": "backup", "component": "c", "token": "t", "impactType": "outage", "interval": "24h"}]`,
+			interval: 24 * time.Hour,
+		},
+		{
+			name:    "missing interval",
+			content: `[{"name": "backup", "component": "c", "token": "t", "impactType": "outage"}]`,
+			fails:   true,
+		},
+		{
+			name:    "zero interval",
+			content: `[{"name": "backup", "component": "c", "token": "t", "impactType": "outage", "interval": "0s"}]`,
+			fails:   true,
+		},
+		{
+			name:    "negative interval",
+			content: `[{"name": "backup", "component": "c", "token": "t", "impactType": "outage", "interval": "-1h"}]`,
+			fails:   true,
+		},
+		{
+			name: "duplicate name",
+			content: `[{"name": "backup", "component": "c", "token": "t", "impactType": "outage", "interval": "1h"},
+				{"name": "backup", "component": "d", "token": "u", "impactType": "outage", "interval": "1h"}]`,
+			fails: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "heartbeats.json")
+			if err := os.WriteFile(path, []byte(test.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			heartbeats, err := LoadHeartbeats(path)
+			if test.fails {
+				if err == nil {
+					t.Errorf("expected an error; got %+v", heartbeats)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if heartbeats[0].Interval != test.interval {
+				t.Errorf("expected interval %s; got %s", test.interval, heartbeats[0].Interval)
+			}
+		})
+	}
+}
+
+func TestRunHeartbeatsRestoresIncidents(t *testing.T) {
+	openedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	s := &ServerImplementation{
+		Heartbeats: []Heartbeat{
+			{Name: "backup", Interval: time.Hour},
+			{Name: "cron", Interval: time.Hour},
+		},
+		HeartbeatStateFile: filepath.Join(t.TempDir(), "heartbeats.json"),
+	}
+	if err := saveState(s.HeartbeatStateFile, map[string]heartbeatIncident{
+		"backup": {IncidentId: "item", OpenedAt: openedAt},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := s.RunHeartbeats(ctx, echo.New().Logger); err != nil {
+		t.Fatal(err)
+	}
+
+	backup := s.heartbeatStore.states["backup"]
+	if backup.incidentId != "item" || !backup.openedAt.Equal(openedAt) {
+		t.Errorf("expected the incident to be restored; got %+v", backup)
+	}
+	if backup.lastSeenAt.After(backup.openedAt) {
+		t.Errorf("expected the restart not to count as heartbeat resolving the incident; got %+v", backup)
+	}
+	if cron := s.heartbeatStore.states["cron"]; cron.incidentId != "" {
+		t.Errorf("expected no incident; got %+v", cron)
+	}
+}
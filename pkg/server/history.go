@@ -0,0 +1,91 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	openapi_types "github.com/deepmap/oapi-codegen/pkg/types"
+	"github.com/joshmue/scs-status-page-openapi/pkg/api"
+	"github.com/labstack/echo/v4"
+)
+
+// maxHistoryDays limits the time frame of a single history request.
+const maxHistoryDays = 366
+
+func (s *ServerImplementation) GetHistory(ctx echo.Context, params api.GetHistoryParams) error {
+	location := s.TimeZone
+	if params.Timezone != nil {
+		var err error
+		location, err = time.LoadLocation(*params.Timezone)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	}
+	now := time.Now().In(location)
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+	if params.End != nil {
+		end = time.Date(params.End.Year(), params.End.Month(), params.End.Day(), 0, 0, 0, 0, location)
+	}
+	start := end.AddDate(0, 0, -89)
+	if params.Start != nil {
+		start = time.Date(params.Start.Year(), params.Start.Month(), params.Start.Day(), 0, 0, 0, 0, location)
+	}
+	if end.Before(start) {
+		return echo.NewHTTPError(http.StatusBadRequest, "expected start to be before end")
+	}
+	if start.AddDate(0, 0, maxHistoryDays).Before(end) {
+		return echo.NewHTTPError(http.StatusBadRequest, "time frame too long")
+	}
+
+	components, err := s.Components(ctx.Request().Context())
+	if err != nil {
+		return upstreamError(ctx, err)
+	}
+	incidents, err := s.Incidents(ctx.Request().Context(), ctx.Logger())
+	if err != nil {
+		return upstreamError(ctx, err)
+	}
+
+	histories := []api.ComponentHistory{}
+	for _, component := range components {
+		history := api.ComponentHistory{
+			ComponentId: component.Id,
+			Days:        []api.DailyStatus{},
+		}
+		// Days are constructed from the calendar date, so they are correct across DST changes
+		for day := start; !day.After(end); day = time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, location) {
+			nextDay := time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, location)
+			status := api.DailyStatus{
+				Date:      openapi_types.Date{Time: time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)},
+				Incidents: []api.Id{},
+			}
+			for i := range incidents {
+				if !contains(incidents[i].Affects, component.Id) || !s.incidentTouches(incidents[i], day, nextDay, now) {
+					continue
+				}
+				status.Incidents = append(status.Incidents, incidents[i].Id)
+				if status.ImpactType == nil || s.impactTypeSeverity(incidents[i].ImpactType) > s.impactTypeSeverity(*status.ImpactType) {
+					status.ImpactType = &incidents[i].ImpactType
+				}
+			}
+			history.Days = append(history.Days, status)
+		}
+		histories = append(histories, history)
+	}
+	return ctx.JSON(200, histories)
+}
+
+// incidentTouches returns whether the incident overlaps with [start, end).
+// Ongoing incidents last until now, resolved incidents without a known end only touch their beginning.
+func (s *ServerImplementation) incidentTouches(incident api.Incident, start time.Time, end time.Time, now time.Time) bool {
+	if incident.BeganAt == nil {
+		return false
+	}
+	incidentEnd := now
+	if incident.EndedAt != nil {
+		incidentEnd = *incident.EndedAt
+	} else if incident.Phase == s.LastPhase {
+		incidentEnd = *incident.BeganAt
+	}
+	return incident.BeganAt.Before(end) && (incidentEnd.After(start) || !incident.BeganAt.Before(start))
+}
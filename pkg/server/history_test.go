@@ -0,0 +1,41 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/joshmue/scs-status-page-openapi/pkg/api"
+)
+
+func TestIncidentTouches(t *testing.T) {
+	s := &ServerImplementation{LastPhase: "Resolved"}
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	nextDay := day.AddDate(0, 0, 1)
+	now := day.Add(36 * time.Hour)
+	at := func(hours int) *time.Time {
+		return timestamp(day.Add(time.Duration(hours) * time.Hour))
+	}
+	tests := []struct {
+		name     string
+		incident api.Incident
+		expected bool
+	}{
+		{"without beginning", api.Incident{Phase: "Investigating"}, false},
+		{"within the day", api.Incident{BeganAt: at(2), EndedAt: at(4)}, true},
+		{"starting the day before", api.Incident{BeganAt: at(-4), EndedAt: at(2)}, true},
+		{"ending at the start of the day", api.Incident{BeganAt: at(-4), EndedAt: at(0)}, false},
+		{"starting at the end of the day", api.Incident{BeganAt: at(24), EndedAt: at(26)}, false},
+		{"spanning the day", api.Incident{BeganAt: at(-4), EndedAt: at(28)}, true},
+		{"ongoing since the day before", api.Incident{BeganAt: at(-4), Phase: "Investigating"}, true},
+		{"resolved without end the day before", api.Incident{BeganAt: at(-4), Phase: "Resolved"}, false},
+		{"resolved without end within the day", api.Incident{BeganAt: at(4), Phase: "Resolved"}, true},
+		{"instantaneous at the start of the day", api.Incident{BeganAt: at(0), EndedAt: at(0)}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if touches := s.incidentTouches(test.incident, day, nextDay, now); touches != test.expected {
+				t.Errorf("expected %t; got %t", test.expected, touches)
+			}
+		})
+	}
+}
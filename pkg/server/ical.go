@@ -0,0 +1,126 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/joshmue/scs-status-page-openapi/pkg/api"
+	"github.com/labstack/echo/v4"
+)
+
+const icalTimeFormat = "20060102T150405Z"
+
+// RegisterICalHandlers adds an iCalendar feed of incidents and maintenance windows.
+func (s *ServerImplementation) RegisterICalHandlers(router api.EchoRouter) {
+	router.GET("/incidents.ics", s.GetIncidentsICal)
+}
+
+// icalText escapes a TEXT value according to RFC 5545.
+func icalText(text string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(text)
+}
+
+// icalWriter writes content lines, folding them at 75 octets.
+type icalWriter struct {
+	strings.Builder
+}
+
+func (w *icalWriter) line(name string, value string) {
+	line := name + ":" + value
+	// Continuation lines start with a space, which counts towards their length
+	limit := 75
+	for len(line) > limit {
+		// Do not split multi-byte characters
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		limit = 74
+	}
+	w.WriteString(line + "\r\n")
+}
+
+// GetIncidentsICal returns incidents and maintenance windows as VEVENTs.
+// They can be limited to ones affecting any of the components given by "component" query parameters.
+func (s *ServerImplementation) GetIncidentsICal(ctx echo.Context) error {
+	components, err := s.Components(ctx.Request().Context())
+	if err != nil {
+		return upstreamError(ctx, err)
+	}
+	incidents, err := s.Incidents(ctx.Request().Context(), ctx.Logger())
+	if err != nil {
+		return upstreamError(ctx, err)
+	}
+	displayNames := map[string]string{}
+	for _, component := range components {
+		displayNames[component.Id] = component.DisplayName
+	}
+	filter := ctx.QueryParams()["component"]
+
+	now := time.Now().UTC()
+	w := &icalWriter{}
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", "-//SCS//Status Page//EN")
+	w.line("CALSCALE", "GREGORIAN")
+	w.line("METHOD", "PUBLISH")
+	w.line("X-WR-CALNAME", "Incidents")
+	for _, incident := range incidents {
+		if incident.BeganAt == nil || !incidentAffectsAny(incident, filter) {
+			continue
+		}
+		affected := []string{}
+		for _, id := range incident.Affects {
+			affected = append(affected, displayNames[id])
+		}
+		end := now
+		if incident.EndedAt != nil {
+			end = *incident.EndedAt
+		} else if incident.Phase == s.LastPhase {
+			end = *incident.BeganAt
+		}
+		summary := incident.Title
+		if s.IsMaintenance(incident) {
+			summary = "Maintenance: " + summary
+		}
+		description := fmt.Sprintf("Phase: %s\nImpact type: %s\nAffected components: %s", incident.Phase, incident.ImpactType, strings.Join(affected, ", "))
+
+		w.line("BEGIN", "VEVENT")
+		w.line("UID", incident.Id+"@scs-status-page")
+		w.line("DTSTAMP", now.Format(icalTimeFormat))
+		if incident.PhaseChangedAt != nil {
+			w.line("LAST-MODIFIED", incident.PhaseChangedAt.UTC().Format(icalTimeFormat))
+		}
+		w.line("DTSTART", incident.BeganAt.UTC().Format(icalTimeFormat))
+		w.line("DTEND", end.UTC().Format(icalTimeFormat))
+		w.line("SUMMARY", icalText(summary))
+		w.line("DESCRIPTION", icalText(description))
+		w.line("CATEGORIES", icalText(incident.ImpactType))
+		w.line("TRANSP", "TRANSPARENT")
+		w.line("END", "VEVENT")
+	}
+	w.line("END", "VCALENDAR")
+	return ctx.Blob(200, "text/calendar; charset=UTF-8", []byte(w.String()))
+}
+
+// incidentAffectsAny returns whether the incident affects any of the components, or true if there are none.
+func incidentAffectsAny(incident api.Incident, componentIds []string) bool {
+	if len(componentIds) == 0 {
+		return true
+	}
+	for _, componentId := range componentIds {
+		if contains(incident.Affects, componentId) {
+			return true
+		}
+	}
+	return false
+}
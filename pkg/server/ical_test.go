@@ -0,0 +1,67 @@
+package server
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/joshmue/scs-status-page-openapi/pkg/api"
+)
+
+func TestICalText(t *testing.T) {
+	if escaped := icalText("a\\b; c, d\r\ne\nf"); escaped != `a\\b\; c\, d\ne\nf` {
+		t.Errorf("got %q", escaped)
+	}
+}
+
+func TestICalWriterFolds(t *testing.T) {
+	for _, value := range []string{
+		strings.Repeat("x", 200),
+		strings.Repeat("ä", 100),
+		"short",
+	} {
+		w := &icalWriter{}
+		w.line("DESCRIPTION", value)
+		output := w.String()
+		if !strings.HasSuffix(output, "\r\n") {
+			t.Errorf("expected the line to end with CRLF; got %q", output)
+		}
+		lines := strings.Split(strings.TrimSuffix(output, "\r\n"), "\r\n")
+		unfolded := ""
+		for i, line := range lines {
+			if len(line) > 75 {
+				t.Errorf("expected lines of at most 75 octets; got %d: %q", len(line), line)
+			}
+			if !utf8.ValidString(line) {
+				t.Errorf("expected characters not to be split; got %q", line)
+			}
+			if i > 0 {
+				if !strings.HasPrefix(line, " ") {
+					t.Errorf("expected continuation lines to start with a space; got %q", line)
+				}
+				line = line[1:]
+			}
+			unfolded += line
+		}
+		if unfolded != "DESCRIPTION:"+value {
+			t.Errorf("expected unfolding to restore the line; got %q", unfolded)
+		}
+	}
+}
+
+func TestIncidentAffectsAny(t *testing.T) {
+	incident := api.Incident{Affects: []string{"a", "b"}}
+	for _, test := range []struct {
+		components []string
+		expected   bool
+	}{
+		{nil, true},
+		{[]string{"b"}, true},
+		{[]string{"c", "a"}, true},
+		{[]string{"c"}, false},
+	} {
+		if affects := incidentAffectsAny(incident, test.components); affects != test.expected {
+			t.Errorf("components %q: expected %t; got %t", test.components, test.expected, affects)
+		}
+	}
+}
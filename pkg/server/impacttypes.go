@@ -2,13 +2,29 @@ package server
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/joshmue/scs-status-page-openapi/pkg/api"
 	"github.com/labstack/echo/v4"
 	"github.com/shurcooL/githubv4"
 )
 
-func (s *ServerImplementation) GetImpacttypes(ctx echo.Context) error {
+// impactTypeSeverity ranks impact types by their position in the configured list
+// of impact types, which is ordered from least to most severe: The first impact
+// type has severity 1, the last one len(s.ImpactTypes). Unknown impact types,
+// including the maintenance impact type, have severity 0 and rank below all configured ones.
+func (s *ServerImplementation) impactTypeSeverity(impactType api.IncidentImpactType) int {
+	for i := range s.ImpactTypes {
+		if s.ImpactTypes[i] == impactType {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// ProjectImpactTypes fetches the options of the impact type field, in order.
+func (s *ServerImplementation) ProjectImpactTypes(ctx context.Context) ([]api.IncidentImpactType, error) {
 	var query struct {
 		Node struct {
 			ProjectV2 struct {
@@ -18,24 +34,100 @@ func (s *ServerImplementation) GetImpacttypes(ctx echo.Context) error {
 							Name string
 						}
 					} `graphql:"... on ProjectV2SingleSelectField"`
-				} `graphql:"field(name: \"Impact Type\")"`
+				} `graphql:"field(name: $fieldname)"`
 			} `graphql:"... on ProjectV2"`
 		} `graphql:"node(id: $projectid)"`
 	}
 	err := s.GithubV4Client.Query(
-		context.Background(),
+		ctx,
 		&query,
 		map[string]interface{}{
 			"projectid": githubv4.ID(s.ProjectID),
+			"fieldname": githubv4.String(s.Fields.ImpactType),
 		},
 	)
 	if err != nil {
-		ctx.Logger().Error(err)
-		return echo.NewHTTPError(500)
+		return nil, err
 	}
 	impactTypes := []api.IncidentImpactType{}
 	for _, phase := range query.Node.ProjectV2.Field.ProjectV2SingleSelectField.Options {
 		impactTypes = append(impactTypes, phase.Name)
 	}
+	return impactTypes, nil
+}
+
+// ValidateImpactTypes checks that the configured impact types are unique and that every
+// impact type referenced by the rest of the configuration is one of them or the maintenance impact type.
+func (s *ServerImplementation) ValidateImpactTypes() error {
+	if len(s.ImpactTypes) == 0 {
+		return fmt.Errorf("expected at least one impact type")
+	}
+	for i, impactType := range s.ImpactTypes {
+		if impactType == "" {
+			return fmt.Errorf("expected impact types to be non-empty")
+		}
+		if contains(s.ImpactTypes[:i], impactType) {
+			return fmt.Errorf(`expected impact type "%s" to be listed once`, impactType)
+		}
+	}
+	if s.Maintenance.ImpactType != "" && contains(s.ImpactTypes, s.Maintenance.ImpactType) {
+		return fmt.Errorf(`expected maintenance impact type "%s" not to be listed as impact type of incidents`, s.Maintenance.ImpactType)
+	}
+	references := map[string][]string{}
+	for impactType := range s.Statuspage.ImpactTypeStatus {
+		references["Statuspage status mapping"] = append(references["Statuspage status mapping"], impactType)
+	}
+	for impactType := range s.Availability.ImpactTypeWeights {
+		references["availability weights"] = append(references["availability weights"], impactType)
+	}
+	for _, rule := range s.Alertmanager.Rules {
+		references["alert rules"] = append(references["alert rules"], rule.ImpactType)
+	}
+	for _, probe := range s.Probes {
+		references[fmt.Sprintf(`probe "%s"`, probe.Name)] = []string{probe.ImpactType}
+	}
+	for _, heartbeat := range s.Heartbeats {
+		references[fmt.Sprintf(`heartbeat "%s"`, heartbeat.Name)] = []string{heartbeat.ImpactType}
+	}
+	for referrer, impactTypes := range references {
+		for _, impactType := range impactTypes {
+			if s.impactTypeSeverity(impactType) == 0 && (impactType == "" || impactType != s.Maintenance.ImpactType) {
+				return fmt.Errorf(`expected impact type "%s" of %s to be one of the configured impact types "%s"`, impactType, referrer, strings.Join(s.ImpactTypes, `", "`))
+			}
+		}
+	}
+	return nil
+}
+
+// GetImpacttypes lists the configured impact types from least to most severe, followed
+// by the maintenance impact type, if any.
+func (s *ServerImplementation) GetImpacttypes(ctx echo.Context) error {
+	projectImpactTypes, err := s.ProjectImpactTypes(ctx.Request().Context())
+	if err != nil {
+		return upstreamError(ctx, err)
+	}
+	impactTypes := []api.ImpactType{}
+	describe := func(name string, severity int, maintenance bool) api.ImpactType {
+		impactType := api.ImpactType{
+			Name:        name,
+			Severity:    severity,
+			Maintenance: maintenance,
+			InProject:   contains(projectImpactTypes, name),
+		}
+		if status, ok := s.Statuspage.ImpactTypeStatus[name]; ok {
+			impactType.StatuspageStatus = &status
+		}
+		if weight, ok := s.Availability.ImpactTypeWeights[name]; ok {
+			weight := float32(weight)
+			impactType.AvailabilityWeight = &weight
+		}
+		return impactType
+	}
+	for _, name := range s.ImpactTypes {
+		impactTypes = append(impactTypes, describe(name, s.impactTypeSeverity(name), false))
+	}
+	if s.Maintenance.ImpactType != "" {
+		impactTypes = append(impactTypes, describe(s.Maintenance.ImpactType, 0, true))
+	}
 	return ctx.JSON(200, impactTypes)
 }
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joshmue/scs-status-page-openapi/pkg/api"
+	"github.com/labstack/echo/v4"
+)
+
+func TestValidateImpactTypes(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		s     *ServerImplementation
+		fails bool
+	}{
+		{"valid", &ServerImplementation{
+			ImpactTypes: []string{"degraded", "outage"},
+			Maintenance: MaintenanceConfig{ImpactType: "maintenance"},
+			Statuspage:  StatuspageConfig{ImpactTypeStatus: map[string]string{"outage": StatuspageMajorOutage, "maintenance": StatuspageUnderMaintenance}},
+			Probes:      []Probe{{Name: "api", ImpactType: "outage"}},
+		}, false},
+		{"none", &ServerImplementation{}, true},
+		{"empty", &ServerImplementation{ImpactTypes: []string{"outage", ""}}, true},
+		{"duplicate", &ServerImplementation{ImpactTypes: []string{"outage", "degraded", "outage"}}, true},
+		{"maintenance listed", &ServerImplementation{ImpactTypes: []string{"maintenance"}, Maintenance: MaintenanceConfig{ImpactType: "maintenance"}}, true},
+		{"unknown in Statuspage mapping", &ServerImplementation{
+			ImpactTypes: []string{"outage"},
+			Statuspage:  StatuspageConfig{ImpactTypeStatus: map[string]string{"performance": StatuspageDegradedPerformance}},
+		}, true},
+		{"unknown in heartbeat", &ServerImplementation{
+			ImpactTypes: []string{"outage"},
+			Heartbeats:  []Heartbeat{{Name: "backup", ImpactType: "missed-backup"}},
+		}, true},
+		{"maintenance without maintenance windows", &ServerImplementation{
+			ImpactTypes: []string{"outage"},
+			Probes:      []Probe{{Name: "api", ImpactType: ""}},
+		}, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.s.ValidateImpactTypes(); (err != nil) != test.fails {
+				t.Errorf("expected failure %t; got %v", test.fails, err)
+			}
+		})
+	}
+}
+
+func TestGetImpacttypesOrderedBySeverity(t *testing.T) {
+	s := &ServerImplementation{
+		GithubV4Client: fakeGithub(t, func(query string, variables map[string]interface{}) string {
+			return `{"data":{"node":{"field":{"options":[{"name":"outage"},{"name":"maintenance"},{"name":"degraded"}]}}}}`
+		}),
+		ProjectID:   "project",
+		Fields:      DefaultFieldNames,
+		ImpactTypes: []string{"degraded", "outage", "disaster"},
+		Maintenance: MaintenanceConfig{ImpactType: "maintenance"},
+		Statuspage:  StatuspageConfig{ImpactTypeStatus: map[string]string{"outage": StatuspageMajorOutage}},
+	}
+	recorder := httptest.NewRecorder()
+	if err := s.GetImpacttypes(echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/impacttypes", nil), recorder)); err != nil {
+		t.Fatal(err)
+	}
+	var impactTypes []api.ImpactType
+	if err := json.Unmarshal(recorder.Body.Bytes(), &impactTypes); err != nil {
+		t.Fatal(err)
+	}
+	expected := []struct {
+		name        string
+		severity    int
+		maintenance bool
+		inProject   bool
+	}{
+		{"degraded", 1, false, true},
+		{"outage", 2, false, true},
+		{"disaster", 3, false, false},
+		{"maintenance", 0, true, true},
+	}
+	if len(impactTypes) != len(expected) {
+		t.Fatalf("expected %d impact types; got %+v", len(expected), impactTypes)
+	}
+	for i, impactType := range impactTypes {
+		if impactType.Name != expected[i].name || impactType.Severity != expected[i].severity || impactType.Maintenance != expected[i].maintenance || impactType.InProject != expected[i].inProject {
+			t.Errorf("expected %+v at position %d; got %+v", expected[i], i, impactType)
+		}
+	}
+	if status := impactTypes[1].StatuspageStatus; status == nil || *status != StatuspageMajorOutage {
+		t.Errorf("expected the Statuspage status of outages; got %v", status)
+	}
+}
@@ -9,31 +9,27 @@ import (
 	"github.com/shurcooL/githubv4"
 )
 
-func ParseTimeOrNil(timeString string) (*time.Time, error) {
-	beganAt, err := time.Parse(time.RFC3339, timeString)
-	if err != nil {
-		return nil, err
-	}
-	return &beganAt, nil
-}
-
-func (i *projectItem) ToIncident(ctx echo.Context) api.Incident {
-	beganAt, err := ParseTimeOrNil(i.BeganAt.ProjectV2ItemFieldTextValue.Text)
-	if err != nil {
-		ctx.Logger().Warn(err)
+// toIncident maps a project item to an incident. Unparseable field values are
+// reported as warnings of the incident and logged to logger.
+func (s *ServerImplementation) toIncident(i *projectItem, logger echo.Logger) api.Incident {
+	warnings := []api.DataQualityWarning{}
+	beganAt, warning := s.itemTimestamp(i.BeganAt, i.BeganAtTime, s.Fields.BeganAt, s.Fields.BeganAtTime, false)
+	if warning != nil {
+		warnings = append(warnings, *warning)
 	}
-	endedAt, err := ParseTimeOrNil(i.EndedAt.ProjectV2ItemFieldTextValue.Text)
-	if err != nil {
-		ctx.Logger().Warn(err)
+	endedAt, warning := s.itemTimestamp(i.EndedAt, i.EndedAtTime, s.Fields.EndedAt, s.Fields.EndedAtTime, true)
+	if warning != nil {
+		warnings = append(warnings, *warning)
 	}
 	incident := api.Incident{
-		Affects:    []string{},
-		Id:         i.Id,
-		Title:      i.Content.Issue.Title,
-		ImpactType: i.ImpactType.ProjectV2ItemFieldSingleSelectValue.Name,
-		Phase:      i.Phase.ProjectV2ItemFieldSingleSelectValue.Name,
-		BeganAt:    beganAt,
-		EndedAt:    endedAt,
+		Affects:        []string{},
+		Id:             i.Id,
+		Title:          i.Content.Issue.Title,
+		ImpactType:     i.ImpactType.ProjectV2ItemFieldSingleSelectValue.Name,
+		Phase:          i.Phase.ProjectV2ItemFieldSingleSelectValue.Name,
+		PhaseChangedAt: i.Phase.ProjectV2ItemFieldSingleSelectValue.UpdatedAt,
+		BeganAt:        beganAt,
+		EndedAt:        endedAt,
 	}
 	for componentKey := range i.Labels.ProjectV2ItemFieldLabelValue.Labels.Nodes {
 		incident.Affects = append(
@@ -41,6 +37,12 @@ func (i *projectItem) ToIncident(ctx echo.Context) api.Incident {
 			i.Labels.ProjectV2ItemFieldLabelValue.Labels.Nodes[componentKey].Id,
 		)
 	}
+	if len(warnings) > 0 {
+		for _, warning := range warnings {
+			logger.Warnf(`Incident "%s": field "%s": %s`, i.Id, warning.Field, warning.Message)
+		}
+		incident.Warnings = &warnings
+	}
 	return incident
 }
 
@@ -53,25 +55,20 @@ type projectItem struct {
 	}
 	Phase struct {
 		ProjectV2ItemFieldSingleSelectValue struct {
-			Name string
+			Name      string
+			UpdatedAt *time.Time
 		} `graphql:"... on ProjectV2ItemFieldSingleSelectValue"`
-	} `graphql:"phase: fieldValueByName(name: \"Status\")"`
+	} `graphql:"phase: fieldValueByName(name: $phasefield)"`
 	ImpactType struct {
 		ProjectV2ItemFieldSingleSelectValue struct {
 			Name string
 		} `graphql:"... on ProjectV2ItemFieldSingleSelectValue"`
-	} `graphql:"impacttype: fieldValueByName(name: \"Impact Type\")"`
-	BeganAt struct {
-		ProjectV2ItemFieldTextValue struct {
-			Text string
-		} `graphql:"... on ProjectV2ItemFieldTextValue"`
-	} `graphql:"beganat: fieldValueByName(name: \"Began At\")"`
-	EndedAt struct {
-		ProjectV2ItemFieldTextValue struct {
-			Text string
-		} `graphql:"... on ProjectV2ItemFieldTextValue"`
-	} `graphql:"endedat: fieldValueByName(name: \"Ended At\")"`
-	Labels struct {
+	} `graphql:"impacttype: fieldValueByName(name: $impacttypefield)"`
+	BeganAt     projectItemTimestampValue `graphql:"beganat: fieldValueByName(name: $beganatfield)"`
+	BeganAtTime projectItemTimestampValue `graphql:"beganattime: fieldValueByName(name: $beganattimefield) @include(if: $withbeganattime)"`
+	EndedAt     projectItemTimestampValue `graphql:"endedat: fieldValueByName(name: $endedatfield)"`
+	EndedAtTime projectItemTimestampValue `graphql:"endedattime: fieldValueByName(name: $endedattimefield) @include(if: $withendedattime)"`
+	Labels      struct {
 		ProjectV2ItemFieldLabelValue struct {
 			Labels struct {
 				Nodes []struct {
@@ -79,54 +76,88 @@ type projectItem struct {
 				}
 			} `graphql:"labels(first:10)"`
 		} `graphql:"... on ProjectV2ItemFieldLabelValue"`
-	} `graphql:"labels: fieldValueByName(name: \"Labels\")"`
+	} `graphql:"labels: fieldValueByName(name: $labelsfield)"`
 }
 
-func (s *ServerImplementation) GetIncidents(ctx echo.Context, params api.GetIncidentsParams) error {
+// IsOngoing returns whether the incident currently affects components.
+// Maintenance windows only do so while they are in progress.
+func (s *ServerImplementation) IsOngoing(incident api.Incident, now time.Time) bool {
+	if s.IsMaintenance(incident) {
+		return s.ToMaintenance(incident, now).Status == api.InProgress
+	}
+	return incident.Phase != s.LastPhase
+}
+
+// Incident fetches a single incident by its project item ID.
+// Unparseable field values are logged to logger. If there is no such incident, the error wraps ErrNotFound.
+func (s *ServerImplementation) Incident(ctx context.Context, logger echo.Logger, incidentId string) (api.Incident, error) {
 	var query struct {
 		Node struct {
-			ProjectV2 struct {
-				Items struct {
-					Nodes []projectItem
-				} `graphql:"items(first: 10)"`
-			} `graphql:"... on ProjectV2"`
-		} `graphql:"node(id: $projectid)"`
+			ProjectV2Item projectItem `graphql:"... on ProjectV2Item"`
+		} `graphql:"node(id: $itemid)"`
 	}
 	err := s.GithubV4Client.Query(
-		context.Background(),
+		ctx,
 		&query,
-		map[string]interface{}{
-			"projectid": githubv4.ID(s.ProjectID),
-		},
+		s.withFieldNames(map[string]interface{}{
+			"itemid": githubv4.ID(incidentId),
+		}),
 	)
 	if err != nil {
-		ctx.Logger().Error(err)
-		return echo.NewHTTPError(500)
+		return api.Incident{}, err
+	}
+	if query.Node.ProjectV2Item.Id == "" {
+		return api.Incident{}, notFoundError(`incident "%s"`, incidentId)
 	}
+	return s.toIncident(&query.Node.ProjectV2Item, logger), nil
+}
+
+// Incidents fetches all items of the project as incidents, page by page.
+// Unparseable field values are logged to logger.
+func (s *ServerImplementation) Incidents(ctx context.Context, logger echo.Logger) ([]api.Incident, error) {
+	variables := s.withFieldNames(map[string]interface{}{
+		"projectid": githubv4.ID(s.ProjectID),
+		"cursor":    (*githubv4.String)(nil),
+	})
 
 	// Map GraphQL output to OpenAPI Spec
 	incidents := []api.Incident{}
-	for itemKey := range query.Node.ProjectV2.Items.Nodes {
-		incidents = append(incidents, query.Node.ProjectV2.Items.Nodes[itemKey].ToIncident(ctx))
+	for {
+		var query struct {
+			Node struct {
+				ProjectV2 struct {
+					Items struct {
+						Nodes    []projectItem
+						PageInfo pageInfo
+					} `graphql:"items(first: 100, after: $cursor)"`
+				} `graphql:"... on ProjectV2"`
+			} `graphql:"node(id: $projectid)"`
+		}
+		err := s.GithubV4Client.Query(ctx, &query, variables)
+		if err != nil {
+			return nil, err
+		}
+		for itemKey := range query.Node.ProjectV2.Items.Nodes {
+			incidents = append(incidents, s.toIncident(&query.Node.ProjectV2.Items.Nodes[itemKey], logger))
+		}
+		if !query.Node.ProjectV2.Items.PageInfo.HasNextPage {
+			return incidents, nil
+		}
+		variables["cursor"] = githubv4.NewString(query.Node.ProjectV2.Items.PageInfo.EndCursor)
+	}
+}
+
+func (s *ServerImplementation) GetIncidents(ctx echo.Context, params api.GetIncidentsParams) error {
+	incidents, err := s.Incidents(ctx.Request().Context(), ctx.Logger())
+	if err != nil {
+		return upstreamError(ctx, err)
 	}
 	return ctx.JSON(200, incidents)
 }
 func (s *ServerImplementation) GetIncident(ctx echo.Context, incidentId string) error {
-	var query struct {
-		Node struct {
-			ProjectV2Item projectItem `graphql:"... on ProjectV2Item"`
-		} `graphql:"node(id: $itemid)"`
-	}
-	err := s.GithubV4Client.Query(
-		context.Background(),
-		&query,
-		map[string]interface{}{
-			"itemid": githubv4.ID(incidentId),
-		},
-	)
+	incident, err := s.Incident(ctx.Request().Context(), ctx.Logger(), incidentId)
 	if err != nil {
-		ctx.Logger().Error(err)
-		return echo.NewHTTPError(500)
+		return upstreamError(ctx, err)
 	}
-	return ctx.JSON(200, query.Node.ProjectV2Item.ToIncident(ctx))
+	return ctx.JSON(200, incident)
 }
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/shurcooL/githubv4"
+)
+
+// fakeGithub serves GraphQL requests with respond, which receives the variables of the request.
+func fakeGithub(t *testing.T, respond func(query string, variables map[string]interface{}) string) *githubv4.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			// Failing the test from the handler goroutine is only allowed with t.Error
+			t.Error(err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		var request struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables"`
+		}
+		if err := json.Unmarshal(body, &request); err != nil {
+			t.Error(err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, respond(request.Query, request.Variables))
+	}))
+	t.Cleanup(server.Close)
+	return githubv4.NewEnterpriseClient(server.URL, server.Client())
+}
+
+func TestIncidentsPaginates(t *testing.T) {
+	requests := 0
+	s := &ServerImplementation{
+		GithubV4Client: fakeGithub(t, func(query string, variables map[string]interface{}) string {
+			requests++
+			switch variables["cursor"] {
+			case nil:
+				return `{"data":{"node":{"items":{"nodes":[{"id":"1"},{"id":"2"}],"pageInfo":{"hasNextPage":true,"endCursor":"c1"}}}}}`
+			case "c1":
+				return `{"data":{"node":{"items":{"nodes":[{"id":"3"}],"pageInfo":{"hasNextPage":false,"endCursor":"c2"}}}}}`
+			}
+			t.Errorf("unexpected cursor %v", variables["cursor"])
+			return `{"data":{}}`
+		}),
+		ProjectID: "project",
+		Fields:    DefaultFieldNames,
+	}
+	incidents, err := s.Incidents(context.Background(), echo.New().Logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(incidents) != 3 || incidents[2].Id != "3" || requests != 2 {
+		t.Errorf("got %d incidents in %d requests: %+v", len(incidents), requests, incidents)
+	}
+}
@@ -0,0 +1,274 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+)
+
+// projectField describes a field of the project along with its options, if it is a single select field.
+type projectField struct {
+	Id       string
+	Name     string
+	DataType string
+	// Options are those of single select fields, in order
+	Options []projectFieldOption
+}
+
+type projectFieldOption struct {
+	Id          string
+	Name        string
+	Color       string
+	Description string
+}
+
+// optionId returns the ID of the option with the given name.
+func (f *projectField) optionId(name string) (string, bool) {
+	for _, option := range f.Options {
+		if option.Name == name {
+			return option.Id, true
+		}
+	}
+	return "", false
+}
+
+// projectFieldFragments selects a field of the project regardless of its type.
+type projectFieldFragments struct {
+	ProjectV2Field struct {
+		Id       string
+		Name     string
+		DataType string
+	} `graphql:"... on ProjectV2Field"`
+	ProjectV2SingleSelectField struct {
+		Id       string
+		Name     string
+		DataType string
+		Options  []projectFieldOption
+	} `graphql:"... on ProjectV2SingleSelectField"`
+}
+
+// field returns the selected field, or nil if there is none.
+func (f *projectFieldFragments) field() *projectField {
+	// Both fragments receive the common fields, so the data type tells them apart
+	if f.ProjectV2SingleSelectField.DataType == "SINGLE_SELECT" {
+		return &projectField{
+			Id:       f.ProjectV2SingleSelectField.Id,
+			Name:     f.ProjectV2SingleSelectField.Name,
+			DataType: f.ProjectV2SingleSelectField.DataType,
+			Options:  f.ProjectV2SingleSelectField.Options,
+		}
+	}
+	if f.ProjectV2Field.Id == "" {
+		return nil
+	}
+	return &projectField{
+		Id:       f.ProjectV2Field.Id,
+		Name:     f.ProjectV2Field.Name,
+		DataType: f.ProjectV2Field.DataType,
+	}
+}
+
+func (s *ServerImplementation) projectField(ctx context.Context, name string) (*projectField, error) {
+	var query struct {
+		Node struct {
+			ProjectV2 struct {
+				Field projectFieldFragments `graphql:"field(name: $fieldname)"`
+			} `graphql:"... on ProjectV2"`
+		} `graphql:"node(id: $projectid)"`
+	}
+	err := s.GithubV4Client.Query(
+		ctx,
+		&query,
+		map[string]interface{}{
+			"projectid": githubv4.ID(s.ProjectID),
+			"fieldname": githubv4.String(name),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	field := query.Node.ProjectV2.Field.field()
+	if field == nil {
+		return nil, fmt.Errorf(`field "%s" not found`, name)
+	}
+	return field, nil
+}
+
+// setItemFieldValue sets the field with the given name of a project item.
+// For single select fields, value is the name of the option to select;
+// for date fields, it is a timestamp whose date is set.
+func (s *ServerImplementation) setItemFieldValue(ctx context.Context, itemId string, fieldName string, value string) error {
+	field, err := s.projectField(ctx, fieldName)
+	if err != nil {
+		return err
+	}
+	return s.setItemField(ctx, itemId, field, value)
+}
+
+// setItemField sets a field of a project item like setItemFieldValue, given the field itself.
+func (s *ServerImplementation) setItemField(ctx context.Context, itemId string, field *projectField, value string) error {
+	fieldValue := githubv4.ProjectV2FieldValue{}
+	switch field.DataType {
+	case "SINGLE_SELECT":
+		optionId, ok := field.optionId(value)
+		if !ok {
+			return fmt.Errorf(`field "%s" has no option "%s"`, field.Name, value)
+		}
+		fieldValue.SingleSelectOptionID = githubv4.NewString(githubv4.String(optionId))
+	case "TEXT":
+		fieldValue.Text = githubv4.NewString(githubv4.String(value))
+	case "DATE":
+		timestamp, err := ParseTimestamp(value, s.TimestampTimeZone)
+		if err != nil || timestamp == nil {
+			return fmt.Errorf(`field "%s" expects a date; got "%s"`, field.Name, value)
+		}
+		year, month, day := timestamp.In(s.TimestampTimeZone).Date()
+		fieldValue.Date = githubv4.NewDate(githubv4.Date{Time: time.Date(year, month, day, 0, 0, 0, 0, time.UTC)})
+	default:
+		return fmt.Errorf(`field "%s" has unsupported data type "%s"`, field.Name, field.DataType)
+	}
+	var mutation struct {
+		UpdateProjectV2ItemFieldValue struct {
+			ProjectV2Item struct {
+				Id string
+			}
+		} `graphql:"updateProjectV2ItemFieldValue(input: $input)"`
+	}
+	return s.GithubV4Client.Mutate(
+		ctx,
+		&mutation,
+		githubv4.UpdateProjectV2ItemFieldValueInput{
+			ProjectID: githubv4.ID(s.ProjectID),
+			ItemID:    githubv4.ID(itemId),
+			FieldID:   githubv4.ID(field.Id),
+			Value:     fieldValue,
+		},
+		nil,
+	)
+}
+
+// newItem describes a project item to be created along with its issue.
+type newItem struct {
+	Title      string
+	Body       string
+	Affects    []string
+	Phase      string
+	ImpactType string
+	BeganAt    *time.Time
+	EndedAt    *time.Time
+}
+
+// createItem creates an issue labeled with the affected components, adds it to the
+// project and sets its fields. All affected components have to be labels of the
+// same repository, which is where the issue will be created; otherwise, the error
+// wraps ErrInvalidInput.
+func (s *ServerImplementation) createItem(ctx context.Context, item newItem) (string, error) {
+	if len(item.Affects) == 0 {
+		return "", fmt.Errorf("expected at least one affected component: %w", ErrInvalidInput)
+	}
+	labelIds := []githubv4.ID{}
+	for _, componentId := range item.Affects {
+		labelIds = append(labelIds, githubv4.ID(componentId))
+	}
+	var labelQuery struct {
+		Nodes []struct {
+			Label struct {
+				Id         string
+				Repository struct {
+					Id string
+				}
+			} `graphql:"... on Label"`
+		} `graphql:"nodes(ids: $labelids)"`
+	}
+	err := s.GithubV4Client.Query(
+		ctx,
+		&labelQuery,
+		map[string]interface{}{
+			"labelids": labelIds,
+		},
+	)
+	if err != nil && isNotFound(err) {
+		return "", fmt.Errorf("expected all affected components to exist: %w", ErrInvalidInput)
+	}
+	if err != nil {
+		return "", err
+	}
+	repositoryId := ""
+	if len(labelQuery.Nodes) != len(labelIds) {
+		return "", fmt.Errorf("expected all affected components to exist: %w", ErrInvalidInput)
+	}
+	for _, node := range labelQuery.Nodes {
+		if node.Label.Repository.Id == "" {
+			return "", fmt.Errorf("expected all affected components to exist: %w", ErrInvalidInput)
+		}
+		if repositoryId != "" && repositoryId != node.Label.Repository.Id {
+			return "", fmt.Errorf("expected all affected components to belong to the same repository: %w", ErrInvalidInput)
+		}
+		repositoryId = node.Label.Repository.Id
+	}
+
+	var createIssue struct {
+		CreateIssue struct {
+			Issue struct {
+				Id string
+			}
+		} `graphql:"createIssue(input: $input)"`
+	}
+	err = s.GithubV4Client.Mutate(
+		ctx,
+		&createIssue,
+		githubv4.CreateIssueInput{
+			RepositoryID: githubv4.ID(repositoryId),
+			Title:        githubv4.String(item.Title),
+			Body:         githubv4.NewString(githubv4.String(item.Body)),
+			LabelIDs:     &labelIds,
+		},
+		nil,
+	)
+	if err != nil {
+		return "", err
+	}
+	var addItem struct {
+		AddProjectV2ItemById struct {
+			Item struct {
+				Id string
+			}
+		} `graphql:"addProjectV2ItemById(input: $input)"`
+	}
+	err = s.GithubV4Client.Mutate(
+		ctx,
+		&addItem,
+		githubv4.AddProjectV2ItemByIdInput{
+			ProjectID: githubv4.ID(s.ProjectID),
+			ContentID: githubv4.ID(createIssue.CreateIssue.Issue.Id),
+		},
+		nil,
+	)
+	if err != nil {
+		return "", err
+	}
+	itemId := addItem.AddProjectV2ItemById.Item.Id
+
+	fields := map[string]string{
+		s.Fields.Phase:      item.Phase,
+		s.Fields.ImpactType: item.ImpactType,
+	}
+	for fieldName, value := range fields {
+		if err := s.setItemFieldValue(ctx, itemId, fieldName, value); err != nil {
+			return itemId, err
+		}
+	}
+	if item.BeganAt != nil {
+		if err := s.setItemTimestamp(ctx, itemId, s.Fields.BeganAt, s.Fields.BeganAtTime, *item.BeganAt, false); err != nil {
+			return itemId, err
+		}
+	}
+	if item.EndedAt != nil {
+		if err := s.setItemTimestamp(ctx, itemId, s.Fields.EndedAt, s.Fields.EndedAtTime, *item.EndedAt, true); err != nil {
+			return itemId, err
+		}
+	}
+	return itemId, nil
+}
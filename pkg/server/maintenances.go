@@ -0,0 +1,180 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/joshmue/scs-status-page-openapi/pkg/api"
+	"github.com/labstack/echo/v4"
+)
+
+// MaintenanceConfig configures how maintenance windows are stored in the project.
+type MaintenanceConfig struct {
+	// ImpactType marks project items as maintenance windows instead of incidents.
+	// Their began at and ended at fields hold the planned start and end.
+	ImpactType string
+	// InProgressPhase is the phase maintenance windows are moved to once they start.
+	// When they end, they are moved to the last phase.
+	InProgressPhase string
+}
+
+// IsMaintenance returns whether the incident is a maintenance window.
+func (s *ServerImplementation) IsMaintenance(incident api.Incident) bool {
+	return s.Maintenance.ImpactType != "" && incident.ImpactType == s.Maintenance.ImpactType
+}
+
+// ToMaintenance converts an incident to a maintenance window, computing its status at the given time.
+func (s *ServerImplementation) ToMaintenance(incident api.Incident, now time.Time) api.Maintenance {
+	maintenance := api.Maintenance{
+		Id:      incident.Id,
+		Title:   incident.Title,
+		Affects: incident.Affects,
+		Phase:   incident.Phase,
+		Status:  api.Scheduled,
+	}
+	if incident.BeganAt != nil {
+		maintenance.PlannedStart = *incident.BeganAt
+	}
+	if incident.EndedAt != nil {
+		maintenance.PlannedEnd = *incident.EndedAt
+	}
+	switch {
+	case incident.Phase == s.LastPhase || (incident.EndedAt != nil && !now.Before(*incident.EndedAt)):
+		maintenance.Status = api.Completed
+	case incident.BeganAt != nil && !now.Before(*incident.BeganAt):
+		maintenance.Status = api.InProgress
+	}
+	return maintenance
+}
+
+// Maintenances fetches all maintenance windows of the project.
+func (s *ServerImplementation) Maintenances(ctx context.Context, logger echo.Logger) ([]api.Maintenance, error) {
+	incidents, err := s.Incidents(ctx, logger)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	maintenances := []api.Maintenance{}
+	for _, incident := range incidents {
+		if s.IsMaintenance(incident) {
+			maintenances = append(maintenances, s.ToMaintenance(incident, now))
+		}
+	}
+	return maintenances, nil
+}
+
+func (s *ServerImplementation) GetMaintenances(ctx echo.Context) error {
+	maintenances, err := s.Maintenances(ctx.Request().Context(), ctx.Logger())
+	if err != nil {
+		return upstreamError(ctx, err)
+	}
+	return ctx.JSON(200, maintenances)
+}
+
+func (s *ServerImplementation) GetMaintenance(ctx echo.Context, maintenanceId string) error {
+	incident, err := s.Incident(ctx.Request().Context(), ctx.Logger(), maintenanceId)
+	if err != nil {
+		return upstreamError(ctx, err)
+	}
+	if !s.IsMaintenance(incident) {
+		return echo.NewHTTPError(http.StatusNotFound, notFoundError(`maintenance "%s"`, maintenanceId).Error())
+	}
+	return ctx.JSON(200, s.ToMaintenance(incident, time.Now()))
+}
+
+func (s *ServerImplementation) CreateMaintenance(ctx echo.Context) error {
+	if err := s.authorize(ctx); err != nil {
+		return err
+	}
+	if s.Maintenance.ImpactType == "" {
+		return echo.NewHTTPError(http.StatusNotImplemented, "maintenance windows are disabled")
+	}
+	var body api.CreateMaintenanceJSONRequestBody
+	if err := ctx.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if body.Title == "" || len(body.Affects) == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "expected title and affected components")
+	}
+	if !body.PlannedStart.Before(body.PlannedEnd) {
+		return echo.NewHTTPError(http.StatusBadRequest, "expected planned start to be before planned end")
+	}
+	phases, err := s.Phases(ctx.Request().Context())
+	if err != nil {
+		return upstreamError(ctx, err)
+	}
+	if len(phases) == 0 {
+		ctx.Logger().Error("could not determine initial phase")
+		return echo.NewHTTPError(500)
+	}
+	description := ""
+	if body.Description != nil {
+		description = *body.Description
+	}
+	itemId, err := s.createItem(ctx.Request().Context(), newItem{
+		Title:      body.Title,
+		Body:       description,
+		Affects:    body.Affects,
+		Phase:      phases[0],
+		ImpactType: s.Maintenance.ImpactType,
+		BeganAt:    &body.PlannedStart,
+		EndedAt:    &body.PlannedEnd,
+	})
+	if errors.Is(err, ErrInvalidInput) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err != nil {
+		return upstreamError(ctx, err)
+	}
+	incident, err := s.Incident(ctx.Request().Context(), ctx.Logger(), itemId)
+	if err != nil {
+		return upstreamError(ctx, err)
+	}
+	return ctx.JSON(201, s.ToMaintenance(incident, time.Now()))
+}
+
+// RunMaintenanceTransitions periodically moves maintenance windows into the
+// in-progress phase once they start and into the last phase once they end.
+// It blocks until ctx is done.
+func (s *ServerImplementation) RunMaintenanceTransitions(ctx context.Context, logger echo.Logger, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.transitionMaintenances(ctx, logger); err != nil {
+			logger.Error(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *ServerImplementation) transitionMaintenances(ctx context.Context, logger echo.Logger) error {
+	maintenances, err := s.Maintenances(ctx, logger)
+	if err != nil {
+		return err
+	}
+	for _, maintenance := range maintenances {
+		phase := maintenance.Phase
+		switch maintenance.Status {
+		case api.InProgress:
+			phase = s.Maintenance.InProgressPhase
+		case api.Completed:
+			phase = s.LastPhase
+		}
+		if phase == "" || phase == maintenance.Phase {
+			continue
+		}
+		logger.Infof(`Moving maintenance "%s" to phase "%s"`, maintenance.Id, phase)
+		if err := s.setItemFieldValue(ctx, maintenance.Id, s.Fields.Phase, phase); err != nil {
+			// Retried on the next transition, without holding up the other maintenance windows
+			logger.Errorf(`Moving maintenance "%s" to phase "%s" failed: %s`, maintenance.Id, phase, err)
+			continue
+		}
+	}
+	return nil
+}
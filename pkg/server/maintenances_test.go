@@ -0,0 +1,125 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestMaintenancePhaseProblem(t *testing.T) {
+	for _, test := range []struct {
+		name            string
+		impactType      string
+		inProgressPhase string
+		problem         bool
+	}{
+		{"disabled maintenance windows", "", "Maintaining", false},
+		{"disabled in-progress transition", "maintenance", "", false},
+		{"existing phase", "maintenance", "In Progress", false},
+		{"missing phase", "maintenance", "Maintaining", true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			s := &ServerImplementation{
+				GithubV4Client: fakeGithub(t, func(query string, variables map[string]interface{}) string {
+					if strings.Contains(query, "repositories(") {
+						return `{"data":{"node":{"repositories":{"nodes":[{"id":"r","labels":{"nodes":[
+							{"id":"api","name":"component:API","issues":{"nodes":[],"pageInfo":{"hasNextPage":false}}}
+						],"pageInfo":{"hasNextPage":false}}}],"pageInfo":{"hasNextPage":false}}}}}`
+					}
+					return `{"data":{"node":{
+						"status":{"options":[{"name":"Investigating"},{"name":"In Progress"},{"name":"Done"}]},
+						"impacttype":{"options":[{"name":"outage"},{"name":"maintenance"}]},
+						"beganat":{"dataType":"TEXT"},
+						"endedat":{"dataType":"TEXT"}}}}`
+				}),
+				ProjectID:       "project",
+				Fields:          DefaultFieldNames,
+				ComponentPrefix: DefaultComponentPrefix,
+				LastPhase:       "Done",
+				ImpactTypes:     []string{"outage"},
+				Maintenance:     MaintenanceConfig{ImpactType: test.impactType, InProgressPhase: test.inProgressPhase},
+			}
+			if test.impactType == "" {
+				s.ImpactTypes = append(s.ImpactTypes, "maintenance")
+			}
+			problems, err := s.ProjectConfigurationProblems(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			checks := []string{}
+			for _, problem := range problems {
+				checks = append(checks, problem.Check)
+			}
+			expected := []string{}
+			if test.problem {
+				expected = append(expected, "maintenance-phase")
+			}
+			if !reflect.DeepEqual(checks, expected) {
+				t.Errorf("expected the checks %q to fail; got %+v", expected, problems)
+			}
+		})
+	}
+}
+
+func TestTransitionMaintenances(t *testing.T) {
+	now := time.Now().UTC()
+	item := func(id string, impactType string, phase string, began time.Duration, ended time.Duration) string {
+		return fmt.Sprintf(`{"id":%q,"phase":{"name":%q},"impacttype":{"name":%q},"beganat":{"text":%q},"endedat":{"text":%q}}`,
+			id, phase, impactType, now.Add(began).Format(time.RFC3339), now.Add(ended).Format(time.RFC3339))
+	}
+	items := strings.Join([]string{
+		item("scheduled", "maintenance", "Investigating", time.Hour, 2*time.Hour),
+		item("started", "maintenance", "Investigating", -time.Hour, time.Hour),
+		item("moved", "maintenance", "In Progress", -time.Hour, time.Hour),
+		item("ended", "maintenance", "In Progress", -2*time.Hour, -time.Hour),
+		item("outage", "outage", "Investigating", -2*time.Hour, -time.Hour),
+	}, ",")
+	for _, test := range []struct {
+		name            string
+		inProgressPhase string
+		moved           []string
+	}{
+		{"in-progress transition", "In Progress", []string{"ended:done", "started:progress"}},
+		{"without in-progress transition", "", []string{"ended:done"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			moved := []string{}
+			s := &ServerImplementation{
+				GithubV4Client: fakeGithub(t, func(query string, variables map[string]interface{}) string {
+					switch {
+					case strings.Contains(query, "items("):
+						return `{"data":{"node":{"items":{"nodes":[` + items + `],"pageInfo":{"hasNextPage":false}}}}}`
+					case strings.Contains(query, "$fieldname"):
+						return `{"data":{"node":{"field":{"id":"phase","name":"Status","dataType":"SINGLE_SELECT","options":[
+							{"id":"investigating","name":"Investigating"},{"id":"progress","name":"In Progress"},{"id":"done","name":"Done"}]}}}}`
+					case strings.Contains(query, "updateProjectV2ItemFieldValue"):
+						input := variables["input"].(map[string]interface{})
+						value := input["value"].(map[string]interface{})
+						moved = append(moved, fmt.Sprintf("%s:%s", input["itemId"], value["singleSelectOptionId"]))
+						return `{"data":{"updateProjectV2ItemFieldValue":{"projectV2Item":{"id":"x"}}}}`
+					}
+					t.Errorf("unexpected query %s", query)
+					return `{"data":{}}`
+				}),
+				ProjectID:         "project",
+				Fields:            DefaultFieldNames,
+				LastPhase:         "Done",
+				TimestampTimeZone: time.UTC,
+				Maintenance:       MaintenanceConfig{ImpactType: "maintenance", InProgressPhase: test.inProgressPhase},
+			}
+			if err := s.transitionMaintenances(context.Background(), echo.New().Logger); err != nil {
+				t.Fatal(err)
+			}
+			sort.Strings(moved)
+			if !reflect.DeepEqual(moved, test.moved) {
+				t.Errorf("expected %q to be moved; got %q", test.moved, moved)
+			}
+		})
+	}
+}
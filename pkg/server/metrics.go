@@ -0,0 +1,185 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joshmue/scs-status-page-openapi/pkg/api"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const metricsNamespace = "scs_status_page"
+
+var (
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP handlers.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "code"})
+	githubRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "github_graphql_requests_total",
+		Help:      "GitHub GraphQL API calls.",
+	}, []string{"operation"})
+	githubErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "github_graphql_errors_total",
+		Help:      "GitHub GraphQL API calls failing in transport, with an HTTP error status or with GraphQL errors.",
+	}, []string{"operation"})
+	githubRateLimitRemaining = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "github_ratelimit_remaining",
+		Help:      "Remaining GitHub API rate limit as reported by the last response.",
+	})
+)
+
+// githubMetricsTransport counts GitHub GraphQL API calls and records the remaining rate limit.
+type githubMetricsTransport struct {
+	base http.RoundTripper
+}
+
+// NewGithubMetricsTransport instruments the transport of the HTTP client used for the GitHub GraphQL API.
+func NewGithubMetricsTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &githubMetricsTransport{base: base}
+}
+
+func (t *githubMetricsTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	operation := "query"
+	if request.GetBody != nil {
+		if body, err := request.GetBody(); err == nil {
+			var payload struct {
+				Query string `json:"query"`
+			}
+			if json.NewDecoder(body).Decode(&payload) == nil && strings.HasPrefix(payload.Query, "mutation") {
+				operation = "mutation"
+			}
+			body.Close()
+		}
+	}
+	githubRequests.WithLabelValues(operation).Inc()
+
+	response, err := t.base.RoundTrip(request)
+	if err != nil {
+		githubErrors.WithLabelValues(operation).Inc()
+		return nil, err
+	}
+	if remaining, err := strconv.ParseFloat(response.Header.Get("X-RateLimit-Remaining"), 64); err == nil {
+		githubRateLimitRemaining.Set(remaining)
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		githubErrors.WithLabelValues(operation).Inc()
+		return response, nil
+	}
+	// GraphQL errors are reported within successful responses
+	content, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		githubErrors.WithLabelValues(operation).Inc()
+		return nil, err
+	}
+	response.Body = io.NopCloser(bytes.NewReader(content))
+	var payload struct {
+		Errors []json.RawMessage `json:"errors"`
+	}
+	if json.Unmarshal(content, &payload) != nil || len(payload.Errors) > 0 {
+		githubErrors.WithLabelValues(operation).Inc()
+	}
+	return response, nil
+}
+
+// MetricsMiddleware records the latency of HTTP handlers by route.
+func (s *ServerImplementation) MetricsMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		start := time.Now()
+		err := next(ctx)
+		code := ctx.Response().Status
+		if httpError, ok := err.(*echo.HTTPError); ok {
+			code = httpError.Code
+		} else if err != nil {
+			code = http.StatusInternalServerError
+		}
+		httpRequestDuration.WithLabelValues(ctx.Request().Method, ctx.Path(), strconv.Itoa(code)).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+var (
+	openIncidentsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "", "open_incidents"),
+		"Ongoing incidents, including maintenance windows in progress, by impact type and phase.",
+		[]string{"impact_type", "phase"}, nil,
+	)
+	componentStatusDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "", "component_status"),
+		"Status of components; 1 for the current status of a component.",
+		[]string{"component", "status"}, nil,
+	)
+	snapshotAgeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "", "snapshot_age_seconds"),
+		"Time since the latest snapshot of components and incidents was taken.",
+		nil, nil,
+	)
+)
+
+// snapshotCollector derives metrics from the latest snapshot at scrape time.
+type snapshotCollector struct {
+	server *ServerImplementation
+}
+
+func (c *snapshotCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- openIncidentsDesc
+	descs <- componentStatusDesc
+	descs <- snapshotAgeDesc
+}
+
+func (c *snapshotCollector) Collect(metrics chan<- prometheus.Metric) {
+	snapshot := c.server.LatestSnapshot()
+	if snapshot == nil {
+		return
+	}
+	metrics <- prometheus.MustNewConstMetric(snapshotAgeDesc, prometheus.GaugeValue, time.Since(snapshot.TakenAt).Seconds())
+
+	open := map[[2]string]int{}
+	now := time.Now()
+	for _, incident := range snapshot.Incidents {
+		if c.server.IsOngoing(incident, now) {
+			open[[2]string{incident.ImpactType, incident.Phase}]++
+		}
+	}
+	for labels, count := range open {
+		metrics <- prometheus.MustNewConstMetric(openIncidentsDesc, prometheus.GaugeValue, float64(count), labels[0], labels[1])
+	}
+
+	for _, component := range snapshot.Components {
+		metrics <- prometheus.MustNewConstMetric(componentStatusDesc, prometheus.GaugeValue, 1, component.Id, snapshot.ComponentStatuses[component.Id])
+	}
+}
+
+// RegisterMetricsHandlers adds a Prometheus endpoint exposing metrics about incidents,
+// components, HTTP handlers and the GitHub GraphQL API.
+// Every call uses its own registry, so it may be called for multiple routers or servers.
+func (s *ServerImplementation) RegisterMetricsHandlers(router api.EchoRouter) {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequestDuration,
+		githubRequests,
+		githubErrors,
+		githubRateLimitRemaining,
+		&snapshotCollector{server: s},
+	)
+	router.GET("/metrics", echo.WrapHandler(promhttp.HandlerFor(registry, promhttp.HandlerOpts{})))
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joshmue/scs-status-page-openapi/pkg/api"
+	"github.com/labstack/echo/v4"
+)
+
+func TestMetricsCountOngoingIncidents(t *testing.T) {
+	now := time.Now()
+	s := &ServerImplementation{
+		LastPhase:   "Resolved",
+		Maintenance: MaintenanceConfig{ImpactType: "maintenance"},
+		snapshot: &Snapshot{
+			TakenAt: now,
+			Incidents: []api.Incident{
+				{Id: "1", ImpactType: "outage", Phase: "Investigating"},
+				{Id: "2", ImpactType: "outage", Phase: "Resolved"},
+				{Id: "3", ImpactType: "maintenance", Phase: "Scheduled", BeganAt: timestamp(now.Add(time.Hour)), EndedAt: timestamp(now.Add(2 * time.Hour))},
+				{Id: "4", ImpactType: "maintenance", Phase: "Scheduled", BeganAt: timestamp(now.Add(-time.Hour)), EndedAt: timestamp(now.Add(time.Hour))},
+			},
+			Components:        []api.Component{{Id: "api"}},
+			ComponentStatuses: map[string]string{"api": "degraded"},
+		},
+	}
+	// Registering again, e.g. for another router, must not panic
+	s.RegisterMetricsHandlers(echo.New())
+	e := echo.New()
+	s.RegisterMetricsHandlers(e)
+
+	recorder := httptest.NewRecorder()
+	e.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := recorder.Body.String()
+	for _, expected := range []string{
+		`scs_status_page_open_incidents{impact_type="outage",phase="Investigating"} 1`,
+		`scs_status_page_open_incidents{impact_type="maintenance",phase="Scheduled"} 1`,
+		`scs_status_page_component_status{component="api",status="degraded"} 1`,
+	} {
+		if !strings.Contains(body, expected+"\n") {
+			t.Errorf("expected %q; got\n%s", expected, body)
+		}
+	}
+	if strings.Contains(body, `phase="Resolved"`) {
+		t.Errorf("expected resolved incidents not to be counted; got\n%s", body)
+	}
+}
@@ -0,0 +1,240 @@
+package server
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"html/template"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joshmue/scs-status-page-openapi/pkg/api"
+	"github.com/labstack/echo/v4"
+)
+
+// pageRecentDays is how long resolved incidents are listed on the status page.
+const pageRecentDays = 7
+
+//go:embed templates/page
+var pageFS embed.FS
+
+// PageConfig configures the HTML status page.
+type PageConfig struct {
+	// ThemeDir contains templates and a style.css overriding the embedded ones by file name, if set.
+	ThemeDir string
+}
+
+// themeFS serves files from the theme directory and falls back to the embedded ones.
+type themeFS struct {
+	theme    fs.FS
+	embedded fs.FS
+}
+
+func (t themeFS) Open(name string) (fs.File, error) {
+	if t.theme != nil {
+		file, err := t.theme.Open(name)
+		if err == nil || !errors.Is(err, fs.ErrNotExist) {
+			return file, err
+		}
+	}
+	return t.embedded.Open(name)
+}
+
+type pageComponent struct {
+	Name   string
+	Status string
+	// StatusClass is the Statuspage component status, used for styling
+	StatusClass string
+}
+
+type pageIncident struct {
+	api.Incident
+	// Components are the display names of the affected components
+	Components  []string
+	StatusClass string
+	Maintenance bool
+}
+
+type pageData struct {
+	PageName    string
+	GeneratedAt time.Time
+	Status      string
+	StatusClass string
+	Components  []pageComponent
+	Ongoing     []pageIncident
+	Upcoming    []pageIncident
+	Recent      []pageIncident
+	RecentDays  int
+	// Incident is set on incident detail pages
+	Incident *pageIncident
+	// Subscription is set on pages confirming or cancelling email subscriptions
+	Subscription *pageSubscription
+}
+
+// pageSubscription is a form posting the token of a link sent to a subscriber.
+type pageSubscription struct {
+	Title  string
+	Action string
+	Token  string
+	Button string
+}
+
+// LoadPageTemplates parses the templates of the HTML status page, preferring those of the theme directory.
+func (s *ServerImplementation) LoadPageTemplates() error {
+	embedded, err := fs.Sub(pageFS, "templates/page")
+	if err != nil {
+		return err
+	}
+	files := themeFS{embedded: embedded}
+	if s.Page.ThemeDir != "" {
+		files.theme = os.DirFS(s.Page.ThemeDir)
+	}
+	s.pageFiles = files
+	s.pageTemplates = map[string]*template.Template{}
+	for _, name := range []string{"index", "incident", "subscriber"} {
+		parsed, err := template.New(name).Funcs(template.FuncMap{
+			"join": strings.Join,
+			"formatTime": func(t time.Time) string {
+				return t.In(s.TimeZone).Format("2006-01-02 15:04 MST")
+			},
+		}).ParseFS(files, "layout.tmpl", name+".tmpl")
+		if err != nil {
+			return err
+		}
+		s.pageTemplates[name] = parsed
+	}
+	return nil
+}
+
+// RegisterPageHandlers adds the HTML status page.
+func (s *ServerImplementation) RegisterPageHandlers(router api.EchoRouter) {
+	router.GET("/", s.GetStatusPage)
+	router.GET("/status/incidents/:incidentId", s.GetStatusPageIncident)
+	router.GET("/status/style.css", s.GetStatusPageStyle)
+}
+
+// pageStatusClass maps the status of a component to a Statuspage component status.
+func (s *ServerImplementation) pageStatusClass(status string) string {
+	switch {
+	case status == ComponentOperational:
+		return StatuspageOperational
+	case s.Maintenance.ImpactType != "" && status == s.Maintenance.ImpactType:
+		return StatuspageUnderMaintenance
+	}
+	return s.statuspageComponentStatus(status)
+}
+
+func (s *ServerImplementation) toPageIncident(incident api.Incident, displayNames map[string]string) pageIncident {
+	converted := pageIncident{
+		Incident:    incident,
+		Components:  []string{},
+		StatusClass: s.pageStatusClass(incident.ImpactType),
+		Maintenance: s.IsMaintenance(incident),
+	}
+	for _, id := range incident.Affects {
+		if name, ok := displayNames[id]; ok {
+			converted.Components = append(converted.Components, name)
+		}
+	}
+	return converted
+}
+
+func (s *ServerImplementation) renderPage(ctx echo.Context, name string, data *pageData) error {
+	var buffer bytes.Buffer
+	if err := s.pageTemplates[name].ExecuteTemplate(&buffer, "layout", data); err != nil {
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(500)
+	}
+	return ctx.HTMLBlob(200, buffer.Bytes())
+}
+
+func (s *ServerImplementation) GetStatusPage(ctx echo.Context) error {
+	components, err := s.Components(ctx.Request().Context())
+	if err != nil {
+		return upstreamError(ctx, err)
+	}
+	incidents, err := s.Incidents(ctx.Request().Context(), ctx.Logger())
+	if err != nil {
+		return upstreamError(ctx, err)
+	}
+	now := time.Now()
+	data := &pageData{
+		PageName:    s.Statuspage.PageName,
+		GeneratedAt: now,
+		Components:  []pageComponent{},
+		Ongoing:     []pageIncident{},
+		Upcoming:    []pageIncident{},
+		Recent:      []pageIncident{},
+		RecentDays:  pageRecentDays,
+	}
+
+	statuses := s.ComponentStatuses(components, incidents, now)
+	displayNames := map[string]string{}
+	data.StatusClass = StatuspageOperational
+	for _, component := range components {
+		displayNames[component.Id] = component.DisplayName
+		status := statuses[component.Id]
+		class := s.pageStatusClass(status)
+		if statuspageComponentStatusSeverity[class] > statuspageComponentStatusSeverity[data.StatusClass] {
+			data.StatusClass = class
+		}
+		data.Components = append(data.Components, pageComponent{
+			Name:        component.DisplayName,
+			Status:      status,
+			StatusClass: class,
+		})
+	}
+	data.Status = statuspageDescriptions[statuspageComponentStatusSeverity[data.StatusClass]]
+
+	recentSince := now.AddDate(0, 0, -pageRecentDays)
+	for _, incident := range incidents {
+		converted := s.toPageIncident(incident, displayNames)
+		switch {
+		case s.IsOngoing(incident, now):
+			data.Ongoing = append(data.Ongoing, converted)
+		case converted.Maintenance:
+			if s.ToMaintenance(incident, now).Status == api.Scheduled {
+				data.Upcoming = append(data.Upcoming, converted)
+			}
+		case incidentUpdatedAt(incident).After(recentSince):
+			data.Recent = append(data.Recent, converted)
+		}
+	}
+	sort.Slice(data.Recent, func(i, j int) bool {
+		return incidentUpdatedAt(data.Recent[i].Incident).After(incidentUpdatedAt(data.Recent[j].Incident))
+	})
+	return s.renderPage(ctx, "index", data)
+}
+
+func (s *ServerImplementation) GetStatusPageIncident(ctx echo.Context) error {
+	incident, err := s.Incident(ctx.Request().Context(), ctx.Logger(), ctx.Param("incidentId"))
+	if err != nil {
+		return upstreamError(ctx, err)
+	}
+	components, err := s.Components(ctx.Request().Context())
+	if err != nil {
+		return upstreamError(ctx, err)
+	}
+	displayNames := map[string]string{}
+	for _, component := range components {
+		displayNames[component.Id] = component.DisplayName
+	}
+	converted := s.toPageIncident(incident, displayNames)
+	return s.renderPage(ctx, "incident", &pageData{
+		PageName:    s.Statuspage.PageName,
+		GeneratedAt: time.Now(),
+		Incident:    &converted,
+	})
+}
+
+func (s *ServerImplementation) GetStatusPageStyle(ctx echo.Context) error {
+	style, err := fs.ReadFile(s.pageFiles, "style.css")
+	if err != nil {
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(500)
+	}
+	return ctx.Blob(200, "text/css; charset=utf-8", style)
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// pageServer returns a server whose project has the component "API" affected by an ongoing outage.
+func pageServer(t *testing.T) *ServerImplementation {
+	item := `{"id":"1","content":{"title":"<b>Database</b> down"},"phase":{"name":"Investigating"},"impacttype":{"name":"connectivity-issues"},
+		"beganat":{"text":"2024-01-02T03:04:05Z"},"labels":{"labels":{"nodes":[{"id":"api"}]}}}`
+	return &ServerImplementation{
+		GithubV4Client: fakeGithub(t, func(query string, variables map[string]interface{}) string {
+			switch {
+			case strings.Contains(query, "repositories("):
+				return `{"data":{"node":{"repositories":{"nodes":[{"id":"r","labels":{"nodes":[
+					{"id":"api","name":"component:API","issues":{"nodes":[],"pageInfo":{"hasNextPage":false}}}
+				],"pageInfo":{"hasNextPage":false}}}],"pageInfo":{"hasNextPage":false}}}}}`
+			case strings.Contains(query, "items("):
+				return `{"data":{"node":{"items":{"nodes":[` + item + `],"pageInfo":{"hasNextPage":false}}}}}`
+			case strings.Contains(query, "$itemid"):
+				return `{"data":{"node":` + item + `}}`
+			}
+			t.Errorf("unexpected query %s", query)
+			return `{"data":{}}`
+		}),
+		ProjectID:       "project",
+		Fields:          DefaultFieldNames,
+		ComponentPrefix: DefaultComponentPrefix,
+		LastPhase:       "Done",
+		ImpactTypes:     []string{"performance-degration", "connectivity-issues"},
+		TimeZone:        time.UTC,
+		Statuspage: StatuspageConfig{
+			PageName:         "Example Status",
+			ImpactTypeStatus: map[string]string{"connectivity-issues": StatuspageMajorOutage},
+		},
+	}
+}
+
+func TestStatusPage(t *testing.T) {
+	s := pageServer(t)
+	if err := s.LoadPageTemplates(); err != nil {
+		t.Fatal(err)
+	}
+	e := echo.New()
+	s.RegisterPageHandlers(e)
+	recorder := httptest.NewRecorder()
+	e.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	body := recorder.Body.String()
+	if recorder.Code != 200 {
+		t.Fatalf("expected the page; got %d %s", recorder.Code, body)
+	}
+	for _, expected := range []string{
+		"Example Status",
+		`<section class="overall status-major_outage">`,
+		`<li class="status-major_outage"><span class="name">API</span> <span class="status">connectivity-issues</span></li>`,
+		`<a href="/status/incidents/1">&lt;b&gt;Database&lt;/b&gt; down</a>`,
+		"2024-01-02 03:04 UTC",
+		"No incidents within the last 7 days.",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected the page to contain %q; got\n%s", expected, body)
+		}
+	}
+	if strings.Contains(body, "<script") {
+		t.Errorf("expected the page to work without JavaScript; got\n%s", body)
+	}
+}
+
+func TestStatusPageTheme(t *testing.T) {
+	s := pageServer(t)
+	s.Page.ThemeDir = t.TempDir()
+	if err := os.WriteFile(filepath.Join(s.Page.ThemeDir, "style.css"), []byte("body { color: teal; }"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(s.Page.ThemeDir, "incident.tmpl"), []byte(`{{define "content"}}<p class="themed">{{.Incident.Title}}</p>{{end}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.LoadPageTemplates(); err != nil {
+		t.Fatal(err)
+	}
+	e := echo.New()
+	s.RegisterPageHandlers(e)
+	for path, expected := range map[string]string{
+		"/status/style.css":   "body { color: teal; }",
+		"/status/incidents/1": `<p class="themed">&lt;b&gt;Database&lt;/b&gt; down</p>`,
+		// Templates missing in the theme directory are embedded
+		"/": `<section class="overall status-major_outage">`,
+	} {
+		recorder := httptest.NewRecorder()
+		e.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		if !strings.Contains(recorder.Body.String(), expected) {
+			t.Errorf("%s: expected %q; got %s", path, expected, recorder.Body.String())
+		}
+	}
+}
@@ -8,7 +8,8 @@ import (
 	"github.com/shurcooL/githubv4"
 )
 
-func (s *ServerImplementation) GetPhases(ctx echo.Context) error {
+// Phases fetches the options of the phase field, in order.
+func (s *ServerImplementation) Phases(ctx context.Context) ([]api.IncidentPhase, error) {
 	var query struct {
 		Node struct {
 			ProjectV2 struct {
@@ -18,24 +19,32 @@ func (s *ServerImplementation) GetPhases(ctx echo.Context) error {
 							Name string
 						}
 					} `graphql:"... on ProjectV2SingleSelectField"`
-				} `graphql:"field(name: \"Status\")"`
+				} `graphql:"field(name: $fieldname)"`
 			} `graphql:"... on ProjectV2"`
 		} `graphql:"node(id: $projectid)"`
 	}
 	err := s.GithubV4Client.Query(
-		context.Background(),
+		ctx,
 		&query,
 		map[string]interface{}{
 			"projectid": githubv4.ID(s.ProjectID),
+			"fieldname": githubv4.String(s.Fields.Phase),
 		},
 	)
 	if err != nil {
-		ctx.Logger().Error(err)
-		return echo.NewHTTPError(500)
+		return nil, err
 	}
 	phases := []api.IncidentPhase{}
 	for _, phase := range query.Node.ProjectV2.Field.ProjectV2SingleSelectField.Options {
 		phases = append(phases, phase.Name)
 	}
+	return phases, nil
+}
+
+func (s *ServerImplementation) GetPhases(ctx echo.Context) error {
+	phases, err := s.Phases(ctx.Request().Context())
+	if err != nil {
+		return upstreamError(ctx, err)
+	}
 	return ctx.JSON(200, phases)
 }
@@ -0,0 +1,325 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/joshmue/scs-status-page-openapi/pkg/api"
+	"github.com/labstack/echo/v4"
+)
+
+// probeResultsSize is the number of results kept per probe.
+const probeResultsSize = 100
+
+// Probe periodically checks a target on behalf of a component.
+type Probe struct {
+	Name      string        `json:"name"`
+	Component api.Id        `json:"component"`
+	Type      api.ProbeType `json:"type"`
+	// Target is a URL for HTTP probes, a "host:port" for TCP probes and a host name for DNS probes
+	Target   string        `json:"target"`
+	Interval time.Duration `json:"-"`
+	Timeout  time.Duration `json:"-"`
+	// FailureThreshold is the number of consecutive failures opening an incident
+	FailureThreshold int    `json:"failureThreshold"`
+	ImpactType       string `json:"impactType"`
+	// ExpectedStatus is the status code HTTP probes expect. If 0, any status below 400 succeeds.
+	ExpectedStatus int `json:"expectedStatus"`
+	// Resolver is the "host:port" of the DNS server used by DNS probes. If empty, the system resolver is used.
+	Resolver string `json:"resolver"`
+}
+
+// LoadProbes reads a JSON list of probes from a file. Intervals and timeouts are
+// given as durations like "30s" and default to one minute and ten seconds.
+func LoadProbes(path string) ([]Probe, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries []struct {
+		Probe
+		Interval string `json:"interval"`
+		Timeout  string `json:"timeout"`
+	}
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return nil, err
+	}
+	probes := []Probe{}
+	names := map[string]bool{}
+	for _, entry := range entries {
+		probe := entry.Probe
+		if probe.Name == "" || names[probe.Name] {
+			return nil, fmt.Errorf(`probe "%s": expected a unique name`, probe.Name)
+		}
+		names[probe.Name] = true
+		if probe.Component == "" || probe.Target == "" || probe.ImpactType == "" {
+			return nil, fmt.Errorf(`probe "%s": expected component, target and impact type`, probe.Name)
+		}
+		switch probe.Type {
+		case api.Http, api.Tcp, api.Dns:
+		default:
+			return nil, fmt.Errorf(`probe "%s": unknown type "%s"`, probe.Name, probe.Type)
+		}
+		probe.Interval = time.Minute
+		if entry.Interval != "" {
+			if probe.Interval, err = time.ParseDuration(entry.Interval); err != nil {
+				return nil, fmt.Errorf(`probe "%s": %w`, probe.Name, err)
+			}
+		}
+		probe.Timeout = 10 * time.Second
+		if entry.Timeout != "" {
+			if probe.Timeout, err = time.ParseDuration(entry.Timeout); err != nil {
+				return nil, fmt.Errorf(`probe "%s": %w`, probe.Name, err)
+			}
+		}
+		// Tickers panic on non-positive intervals
+		if probe.Interval <= 0 || probe.Timeout <= 0 {
+			return nil, fmt.Errorf(`probe "%s": expected positive interval and timeout`, probe.Name)
+		}
+		if probe.FailureThreshold <= 0 {
+			probe.FailureThreshold = 3
+		}
+		probes = append(probes, probe)
+	}
+	return probes, nil
+}
+
+type probeState struct {
+	results             []api.ProbeResult
+	consecutiveFailures int
+	failingSince        time.Time
+	incidentId          string
+}
+
+// probeStore holds the state of all probes by name.
+type probeStore struct {
+	mutex  sync.Mutex
+	states map[string]*probeState
+}
+
+// saveProbeIncidents persists the IDs of incidents opened by probes. The caller must hold the mutex.
+func (s *ServerImplementation) saveProbeIncidents() error {
+	incidents := map[string]string{}
+	for name, state := range s.probeStore.states {
+		if state.incidentId != "" {
+			incidents[name] = state.incidentId
+		}
+	}
+	return saveState(s.ProbeStateFile, incidents)
+}
+
+// RunProbes runs all probes on their intervals, opening an incident once a probe
+// reaches its failure threshold and resolving it once the probe succeeds again,
+// even if it was opened before a restart. It blocks until ctx is done.
+func (s *ServerImplementation) RunProbes(ctx context.Context, logger echo.Logger) error {
+	incidents := map[string]string{}
+	if err := loadState(s.ProbeStateFile, &incidents); err != nil {
+		return err
+	}
+	s.probeStore.mutex.Lock()
+	s.probeStore.states = map[string]*probeState{}
+	for _, probe := range s.Probes {
+		s.probeStore.states[probe.Name] = &probeState{results: []api.ProbeResult{}, incidentId: incidents[probe.Name]}
+	}
+	s.probeStore.mutex.Unlock()
+
+	var wg sync.WaitGroup
+	for _, probe := range s.Probes {
+		wg.Add(1)
+		go func(probe Probe) {
+			defer wg.Done()
+			ticker := time.NewTicker(probe.Interval)
+			defer ticker.Stop()
+			for {
+				s.runProbe(ctx, logger, probe)
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}(probe)
+	}
+	wg.Wait()
+	return nil
+}
+
+func (s *ServerImplementation) runProbe(ctx context.Context, logger echo.Logger, probe Probe) {
+	result := checkProbe(ctx, probe)
+
+	s.probeStore.mutex.Lock()
+	state := s.probeStore.states[probe.Name]
+	state.results = append(state.results, result)
+	if len(state.results) > probeResultsSize {
+		state.results = state.results[len(state.results)-probeResultsSize:]
+	}
+	if result.Success {
+		state.consecutiveFailures = 0
+	} else {
+		if state.consecutiveFailures == 0 {
+			state.failingSince = result.CheckedAt
+		}
+		state.consecutiveFailures++
+	}
+	consecutiveFailures, failingSince, incidentId := state.consecutiveFailures, state.failingSince, state.incidentId
+	s.probeStore.mutex.Unlock()
+
+	switch {
+	case !result.Success && consecutiveFailures >= probe.FailureThreshold && incidentId == "":
+		logger.Infof(`Probe "%s" failed %d times, opening incident`, probe.Name, consecutiveFailures)
+		incidentId, err := s.openProbeIncident(ctx, probe, failingSince, *result.Error)
+		if err != nil {
+			logger.Errorf(`Opening incident for probe "%s" failed: %s`, probe.Name, err)
+		}
+		s.setProbeIncident(logger, probe, incidentId)
+	case result.Success && incidentId != "":
+		logger.Infof(`Probe "%s" recovered, resolving incident "%s"`, probe.Name, incidentId)
+		err := s.setItemTimestamp(ctx, incidentId, s.Fields.EndedAt, s.Fields.EndedAtTime, result.CheckedAt, true)
+		if err == nil {
+			err = s.setItemFieldValue(ctx, incidentId, s.Fields.Phase, s.LastPhase)
+		}
+		if err != nil {
+			// Retried after the next success
+			logger.Errorf(`Resolving incident "%s" of probe "%s" failed: %s`, incidentId, probe.Name, err)
+			return
+		}
+		s.setProbeIncident(logger, probe, "")
+	}
+}
+
+func (s *ServerImplementation) setProbeIncident(logger echo.Logger, probe Probe, incidentId string) {
+	s.probeStore.mutex.Lock()
+	defer s.probeStore.mutex.Unlock()
+	s.probeStore.states[probe.Name].incidentId = incidentId
+	if err := s.saveProbeIncidents(); err != nil {
+		logger.Error(err)
+	}
+}
+
+func (s *ServerImplementation) openProbeIncident(ctx context.Context, probe Probe, failingSince time.Time, lastError string) (string, error) {
+	phases, err := s.Phases(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(phases) == 0 {
+		return "", fmt.Errorf("could not determine initial phase")
+	}
+	// Partially created items are returned along with the error, so they are resolved nonetheless
+	return s.createItem(ctx, newItem{
+		Title:      fmt.Sprintf(`Probe "%s" failing`, probe.Name),
+		Body:       fmt.Sprintf("The %s probe of `%s` failed %d times in a row.\n\nLast error: %s", probe.Type, probe.Target, probe.FailureThreshold, lastError),
+		Affects:    []string{probe.Component},
+		Phase:      phases[0],
+		ImpactType: probe.ImpactType,
+		BeganAt:    &failingSince,
+	})
+}
+
+// checkProbe checks the target of a probe once.
+func checkProbe(ctx context.Context, probe Probe) api.ProbeResult {
+	ctx, cancel := context.WithTimeout(ctx, probe.Timeout)
+	defer cancel()
+	start := time.Now()
+	var err error
+	switch probe.Type {
+	case api.Http:
+		err = checkHTTP(ctx, probe)
+	case api.Tcp:
+		err = checkTCP(ctx, probe)
+	case api.Dns:
+		err = checkDNS(ctx, probe)
+	}
+	result := api.ProbeResult{
+		CheckedAt:       start,
+		Success:         err == nil,
+		DurationSeconds: time.Since(start).Seconds(),
+	}
+	if err != nil {
+		message := err.Error()
+		result.Error = &message
+	}
+	return result
+}
+
+func checkHTTP(ctx context.Context, probe Probe) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, probe.Target, nil)
+	if err != nil {
+		return err
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	if probe.ExpectedStatus != 0 && response.StatusCode != probe.ExpectedStatus {
+		return fmt.Errorf("expected status code %d, got %d", probe.ExpectedStatus, response.StatusCode)
+	}
+	if probe.ExpectedStatus == 0 && response.StatusCode >= 400 {
+		return fmt.Errorf("unexpected status code %d", response.StatusCode)
+	}
+	return nil
+}
+
+func checkTCP(ctx context.Context, probe Probe) error {
+	var dialer net.Dialer
+	connection, err := dialer.DialContext(ctx, "tcp", probe.Target)
+	if err != nil {
+		return err
+	}
+	return connection.Close()
+}
+
+func checkDNS(ctx context.Context, probe Probe) error {
+	resolver := net.DefaultResolver
+	if probe.Resolver != "" {
+		resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network string, address string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, network, probe.Resolver)
+			},
+		}
+	}
+	addresses, err := resolver.LookupHost(ctx, probe.Target)
+	if err != nil {
+		return err
+	}
+	if len(addresses) == 0 {
+		return fmt.Errorf("no addresses found")
+	}
+	return nil
+}
+
+func (s *ServerImplementation) GetComponentProbes(ctx echo.Context, componentId string) error {
+	s.probeStore.mutex.Lock()
+	defer s.probeStore.mutex.Unlock()
+	statuses := []api.ProbeStatus{}
+	for _, probe := range s.Probes {
+		if probe.Component != componentId {
+			continue
+		}
+		status := api.ProbeStatus{
+			Name:        probe.Name,
+			ComponentId: probe.Component,
+			Type:        probe.Type,
+			Target:      probe.Target,
+			Results:     []api.ProbeResult{},
+		}
+		if state, ok := s.probeStore.states[probe.Name]; ok {
+			status.ConsecutiveFailures = state.consecutiveFailures
+			status.Results = append(status.Results, state.results...)
+			if state.incidentId != "" {
+				incidentId := state.incidentId
+				status.IncidentId = &incidentId
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return ctx.JSON(200, statuses)
+}
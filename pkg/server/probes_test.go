@@ -0,0 +1,130 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joshmue/scs-status-page-openapi/pkg/api"
+)
+
+func TestLoadProbes(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		interval time.Duration
+		timeout  time.Duration
+		fails    bool
+	}{
+		{
+			name:     "defaults",
+			content:  `[{"name": "api", "component": "c", "type": "http", "target": "http://api", "impactType": "outage"}]`,
+			interval: time.Minute,
+			timeout:  10 * time.Second,
+		},
+		{
+			name:     "durations",
+			content:  `[{"name": "api", "component": "c", "type": "tcp", "target": "api:443", "impactType": "outage", "interval": "30s", "timeout": "2s"}]`,
+			interval: 30 * time.Second,
+			timeout:  2 * time.Second,
+		},
+		{
+			name:    "zero interval",
+			content: `[{"name": "api", "component": "c", "type": "http", "target": "http://api", "impactType": "outage", "interval": "0s"}]`,
+			fails:   true,
+		},
+		{
+			name:    "negative timeout",
+			content: `[{"name": "api", "component": "c", "type": "http", "target": "http://api", "impactType": "outage", "timeout": "-1s"}]`,
+			fails:   true,
+		},
+		{
+			name:    "unknown type",
+			content: `[{"name": "api", "component": "c", "type": "icmp", "target": "api", "impactType": "outage"}]`,
+			fails:   true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "probes.json")
+			if err := os.WriteFile(path, []byte(test.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			probes, err := LoadProbes(path)
+			if test.fails {
+				if err == nil {
+					t.Errorf("expected an error; got %+v", probes)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if probes[0].Interval != test.interval || probes[0].Timeout != test.timeout {
+				t.Errorf("expected interval %s and timeout %s; got %s and %s", test.interval, test.timeout, probes[0].Interval, probes[0].Timeout)
+			}
+		})
+	}
+}
+
+func TestCheckProbe(t *testing.T) {
+	web := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusOK)
+		case "/accepted":
+			w.WriteHeader(http.StatusAccepted)
+		case "/slow":
+			time.Sleep(500 * time.Millisecond)
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer web.Close()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	open := listener.Addr().String()
+	defer listener.Close()
+	closing, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := closing.Addr().String()
+	closing.Close()
+
+	tests := []struct {
+		name    string
+		probe   Probe
+		success bool
+	}{
+		{"http ok", Probe{Type: api.Http, Target: web.URL + "/ok"}, true},
+		{"http error status", Probe{Type: api.Http, Target: web.URL + "/down"}, false},
+		{"http expected status", Probe{Type: api.Http, Target: web.URL + "/accepted", ExpectedStatus: http.StatusAccepted}, true},
+		{"http unexpected status", Probe{Type: api.Http, Target: web.URL + "/ok", ExpectedStatus: http.StatusAccepted}, false},
+		{"http timeout", Probe{Type: api.Http, Target: web.URL + "/slow", Timeout: 100 * time.Millisecond}, false},
+		{"tcp open", Probe{Type: api.Tcp, Target: open}, true},
+		{"tcp closed", Probe{Type: api.Tcp, Target: closed}, false},
+		{"dns", Probe{Type: api.Dns, Target: "localhost"}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.probe.Timeout == 0 {
+				test.probe.Timeout = 5 * time.Second
+			}
+			result := checkProbe(context.Background(), test.probe)
+			if result.Success != test.success {
+				t.Errorf("expected success %t; got %+v", test.success, result)
+			}
+			if !result.Success && (result.Error == nil || *result.Error == "") {
+				t.Errorf("expected failures to have an error; got %+v", result)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/joshmue/scs-status-page-openapi/pkg/api"
+	"github.com/labstack/echo/v4"
+)
+
+// MIMEApplicationProblemJSON is the content type of RFC 7807 problem details.
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+var (
+	// ErrNotFound is returned when a requested component or incident does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidInput is returned when data to be written is inconsistent with the project.
+	ErrInvalidInput = errors.New("invalid input")
+)
+
+// isNotFound reports whether GitHub could not resolve a node ID.
+func isNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound) || strings.Contains(err.Error(), "Could not resolve to a node")
+}
+
+// isTimeout reports whether a request to GitHub timed out.
+func isTimeout(err error) bool {
+	var netError net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netError) && netError.Timeout())
+}
+
+// notFoundError wraps ErrNotFound with what was not found.
+func notFoundError(format string, args ...interface{}) error {
+	return fmt.Errorf("%s: %w", fmt.Sprintf(format, args...), ErrNotFound)
+}
+
+// upstreamError maps errors of fetching data from GitHub to HTTP errors:
+// Unknown IDs are 404, timeouts are 504 and any other failure is 502.
+func upstreamError(ctx echo.Context, err error) error {
+	switch {
+	case isNotFound(err):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case isTimeout(err):
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusGatewayTimeout, "GitHub did not respond in time")
+	default:
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusBadGateway, "fetching data from GitHub failed")
+	}
+}
+
+// HTTPErrorHandler renders errors as RFC 7807 problem details, including those
+// returned by the generated wrappers for invalid parameters.
+func (s *ServerImplementation) HTTPErrorHandler(err error, ctx echo.Context) {
+	if ctx.Response().Committed {
+		return
+	}
+	httpError, ok := err.(*echo.HTTPError)
+	if !ok {
+		ctx.Logger().Error(err)
+		httpError = echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	problem := api.Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(httpError.Code),
+		Status: httpError.Code,
+	}
+	if detail := fmt.Sprint(httpError.Message); detail != problem.Title {
+		problem.Detail = &detail
+	}
+	instance := ctx.Request().URL.Path
+	problem.Instance = &instance
+
+	var writeErr error
+	if ctx.Request().Method == http.MethodHead {
+		writeErr = ctx.NoContent(httpError.Code)
+	} else {
+		ctx.Response().Header().Set(echo.HeaderContentType, MIMEApplicationProblemJSON)
+		writeErr = ctx.JSON(httpError.Code, problem)
+	}
+	if writeErr != nil {
+		ctx.Logger().Error(writeErr)
+	}
+}
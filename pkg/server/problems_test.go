@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joshmue/scs-status-page-openapi/pkg/api"
+	"github.com/labstack/echo/v4"
+)
+
+func TestUpstreamError(t *testing.T) {
+	for _, test := range []struct {
+		err  error
+		code int
+	}{
+		{notFoundError(`incident "%s"`, "1"), http.StatusNotFound},
+		{fmt.Errorf("query: %w", notFoundError("component")), http.StatusNotFound},
+		{errors.New(`Could not resolve to a node with the global id of "1"`), http.StatusNotFound},
+		{fmt.Errorf("query: %w", context.DeadlineExceeded), http.StatusGatewayTimeout},
+		{errors.New("non-200 OK status code: 500"), http.StatusBadGateway},
+	} {
+		ctx := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+		httpError, ok := upstreamError(ctx, test.err).(*echo.HTTPError)
+		if !ok || httpError.Code != test.code {
+			t.Errorf("%q: expected status %d; got %v", test.err, test.code, httpError)
+		}
+	}
+}
+
+func TestHTTPErrorHandler(t *testing.T) {
+	s := &ServerImplementation{}
+	for _, test := range []struct {
+		name   string
+		method string
+		err    error
+		code   int
+		detail *string
+	}{
+		{"with detail", http.MethodGet, echo.NewHTTPError(http.StatusNotFound, `incident "1": not found`), http.StatusNotFound, stringPointer(`incident "1": not found`)},
+		{"without detail", http.MethodGet, echo.NewHTTPError(http.StatusNotFound), http.StatusNotFound, nil},
+		{"other errors", http.MethodGet, errors.New("internal"), http.StatusInternalServerError, nil},
+		{"HEAD", http.MethodHead, echo.NewHTTPError(http.StatusNotFound), http.StatusNotFound, nil},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			s.HTTPErrorHandler(test.err, echo.New().NewContext(httptest.NewRequest(test.method, "/incidents/1", nil), recorder))
+			if recorder.Code != test.code {
+				t.Errorf("expected status %d; got %d", test.code, recorder.Code)
+			}
+			if test.method == http.MethodHead {
+				if recorder.Body.Len() != 0 {
+					t.Errorf("expected no body; got %s", recorder.Body.String())
+				}
+				return
+			}
+			if contentType := recorder.Header().Get(echo.HeaderContentType); contentType != MIMEApplicationProblemJSON {
+				t.Errorf("expected content type %s; got %s", MIMEApplicationProblemJSON, contentType)
+			}
+			var problem api.Problem
+			if err := json.Unmarshal(recorder.Body.Bytes(), &problem); err != nil {
+				t.Fatal(err)
+			}
+			if problem.Status != test.code || problem.Title != http.StatusText(test.code) || problem.Instance == nil || *problem.Instance != "/incidents/1" {
+				t.Errorf("unexpected problem %+v", problem)
+			}
+			if (problem.Detail == nil) != (test.detail == nil) || (problem.Detail != nil && *problem.Detail != *test.detail) {
+				t.Errorf("expected detail %v; got %v", test.detail, problem.Detail)
+			}
+		})
+	}
+}
+
+func stringPointer(detail string) *string {
+	return &detail
+}
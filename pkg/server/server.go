@@ -2,9 +2,17 @@ package server
 
 import (
 	"context"
+	"crypto/subtle"
 	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/joshmue/scs-status-page-openapi/pkg/api"
+	"github.com/labstack/echo/v4"
 	"github.com/shurcooL/githubv4"
 )
 
@@ -14,8 +22,65 @@ type ServerImplementation struct {
 	ProjectOwnerIsOrg bool
 	ProjectNumber     int64
 	ProjectID         string
-	ImpactTypes       []string
-	LastPhase         string
+	// ImpactTypes is ordered from least to most severe
+	ImpactTypes []string
+	LastPhase   string
+	// Fields names the project fields holding the data of incidents
+	Fields FieldNames
+	// ComponentPrefix marks labels as components and is stripped from their display names
+	ComponentPrefix string
+	// TimeZone determines day boundaries
+	TimeZone *time.Location
+	// TimestampTimeZone interprets date fields and timestamps without offset
+	TimestampTimeZone *time.Location
+	Statuspage        StatuspageConfig
+	Maintenance       MaintenanceConfig
+	Availability      AvailabilityConfig
+	Webhooks          WebhookConfig
+	Email             EmailConfig
+	// ChatChannels receive incident notifications via incoming webhooks
+	ChatChannels []ChatChannel
+	Alertmanager AlertmanagerConfig
+	// Probes check targets on behalf of components and open incidents on failures
+	Probes []Probe
+	// ProbeStateFile persists which probe opened which incident across restarts, if set
+	ProbeStateFile string
+	// Heartbeats expect services to check in and open incidents once they stop
+	Heartbeats []Heartbeat
+	// HeartbeatStateFile persists which heartbeat opened which incident across restarts, if set
+	HeartbeatStateFile string
+	Page               PageConfig
+	Caching            CachingConfig
+	// PublicURL is the URL the server is reachable at, used for links in notifications and feeds
+	PublicURL string
+	// AdminToken is the bearer token required for write operations.
+	// If empty, write operations are disabled.
+	AdminToken string
+
+	snapshotMutex   sync.RWMutex
+	snapshot        *Snapshot
+	events          eventLog
+	webhookStore    webhookStore
+	subscriberStore subscriberStore
+	alertStore      alertStore
+	probeStore      probeStore
+	heartbeatStore  heartbeatStore
+	pageFiles       fs.FS
+	cachingStore    cachingStore
+	pageTemplates   map[string]*template.Template
+	diagnosisCache  diagnosisCache
+}
+
+// authorize checks the bearer token of requests to write operations.
+func (s *ServerImplementation) authorize(ctx echo.Context) error {
+	if s.AdminToken == "" {
+		return echo.NewHTTPError(http.StatusForbidden, "write operations are disabled")
+	}
+	token := strings.TrimPrefix(ctx.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(s.AdminToken)) != 1 {
+		return echo.NewHTTPError(http.StatusUnauthorized)
+	}
+	return nil
 }
 
 func (s *ServerImplementation) FillProjectID() error {
@@ -48,89 +113,221 @@ func (s *ServerImplementation) FillProjectID() error {
 	return nil
 }
 
+// ConfigurationError lists every way the project deviates from what the server expects.
+type ConfigurationError struct {
+	Problems []api.ConfigurationProblem
+}
+
+func (e *ConfigurationError) Error() string {
+	lines := []string{fmt.Sprintf("found %d problem(s) with the project configuration:", len(e.Problems))}
+	for _, problem := range e.Problems {
+		line := fmt.Sprintf("- %s: %s", problem.Check, problem.Message)
+		if problem.Hint != nil {
+			line += fmt.Sprintf(" (hint: %s)", *problem.Hint)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// EnsureProjectConfiguration checks whether the project meets the expectations of the server.
+// If it does not, the error is a *ConfigurationError listing all problems.
 func (s *ServerImplementation) EnsureProjectConfiguration() error {
+	problems, err := s.ProjectConfigurationProblems(context.Background())
+	if err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return &ConfigurationError{Problems: problems}
+	}
+	return nil
+}
+
+func configurationProblem(check string, message string, hint string) api.ConfigurationProblem {
+	return api.ConfigurationProblem{Check: check, Message: message, Hint: &hint}
+}
+
+// ProjectConfigurationProblems returns every way the project deviates from what the server expects.
+// The error is set if the project could not be queried at all.
+func (s *ServerImplementation) ProjectConfigurationProblems(ctx context.Context) ([]api.ConfigurationProblem, error) {
+	if s.ProjectID == "" {
+		return []api.ConfigurationProblem{configurationProblem(
+			"project",
+			fmt.Sprintf(`expected project %d of "%s" to exist`, s.ProjectNumber, s.ProjectOwner),
+			"check the -github.project.owner and -github.project.number flags and the permissions of the token",
+		)}, nil
+	}
 	// Make a single query to assess all relevant factors
 	var query struct {
 		Node struct {
 			ProjectV2 struct {
-				Repositories struct {
-					Nodes []struct {
-						Labels struct {
-							Nodes []struct {
-								Name string
-							}
-						} `graphql:"labels(first: 10)"`
-					}
-				} `graphql:"repositories(first: 10)"`
 				StatusField struct {
 					ProjectV2SingleSelectField struct {
 						Options []struct {
 							Name string
 						}
 					} `graphql:"... on ProjectV2SingleSelectField"`
-				} `graphql:"status: field(name: \"Status\")"`
+				} `graphql:"status: field(name: $phasefield)"`
 				ImpactTypeField struct {
 					ProjectV2SingleSelectField struct {
 						Options []struct {
 							Name string
 						}
 					} `graphql:"... on ProjectV2SingleSelectField"`
-				} `graphql:"impacttype: field(name: \"Impact Type\")"`
+				} `graphql:"impacttype: field(name: $impacttypefield)"`
 				BeganAtField struct {
 					ProjectV2Field struct {
 						DataType string
 					} `graphql:"... on ProjectV2Field"`
-				} `graphql:"beganat: field(name: \"Began At\")"`
+				} `graphql:"beganat: field(name: $beganatfield)"`
 				EndedAtField struct {
 					ProjectV2Field struct {
 						DataType string
 					} `graphql:"... on ProjectV2Field"`
-				} `graphql:"endedat: field(name: \"Ended At\")"`
+				} `graphql:"endedat: field(name: $endedatfield)"`
+				BeganAtTimeField struct {
+					ProjectV2Field struct {
+						DataType string
+					} `graphql:"... on ProjectV2Field"`
+				} `graphql:"beganattime: field(name: $beganattimefield) @include(if: $withbeganattime)"`
+				EndedAtTimeField struct {
+					ProjectV2Field struct {
+						DataType string
+					} `graphql:"... on ProjectV2Field"`
+				} `graphql:"endedattime: field(name: $endedattimefield) @include(if: $withendedattime)"`
 			} `graphql:"... on ProjectV2"`
 		} `graphql:"node(id: $projectid)"`
 	}
 	err := s.GithubV4Client.Query(
-		context.Background(),
+		ctx,
 		&query,
 		map[string]interface{}{
-			"projectid": githubv4.ID(s.ProjectID),
+			"projectid":        githubv4.ID(s.ProjectID),
+			"phasefield":       githubv4.String(s.Fields.Phase),
+			"impacttypefield":  githubv4.String(s.Fields.ImpactType),
+			"beganatfield":     githubv4.String(s.Fields.BeganAt),
+			"endedatfield":     githubv4.String(s.Fields.EndedAt),
+			"beganattimefield": githubv4.String(s.Fields.BeganAtTime),
+			"endedattimefield": githubv4.String(s.Fields.EndedAtTime),
+			"withbeganattime":  githubv4.Boolean(s.Fields.BeganAtTime != ""),
+			"withendedattime":  githubv4.Boolean(s.Fields.EndedAtTime != ""),
 		},
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	project := query.Node.ProjectV2
+	problems := []api.ConfigurationProblem{}
 	// Check components
-	componentFound := false
-	for _, repo := range query.Node.ProjectV2.Repositories.Nodes {
-		for _, label := range repo.Labels.Nodes {
-			if strings.HasPrefix(label.Name, "component:") {
-				componentFound = true
-			}
-		}
+	labels, err := s.componentLabels(ctx, false)
+	if err != nil {
+		return nil, err
 	}
-	if !componentFound {
-		return fmt.Errorf("expected components, got none")
+	if len(labels) == 0 {
+		problems = append(problems, configurationProblem(
+			"components",
+			"expected components, got none",
+			fmt.Sprintf(`link a repository to the project and add a label named "%s<name>" to it for every component`, s.ComponentPrefix),
+		))
 	}
-	// Check "Status" field
-	phaseOptions := query.Node.ProjectV2.StatusField.ProjectV2SingleSelectField.Options
+	// Check phase field
+	phaseOptions := project.StatusField.ProjectV2SingleSelectField.Options
 	if len(phaseOptions) == 0 {
-		return fmt.Errorf(`expected to have phases encoded as fields of "Status"; not having any`)
+		problems = append(problems, configurationProblem(
+			"phases",
+			fmt.Sprintf(`expected to have phases encoded as fields of "%s"; not having any`, s.Fields.Phase),
+			fmt.Sprintf(`add the phases as options to the single select field "%s", ending with "%s"`, s.Fields.Phase, s.LastPhase),
+		))
+	} else if lastPhase := phaseOptions[len(phaseOptions)-1].Name; lastPhase != s.LastPhase {
+		problems = append(problems, configurationProblem(
+			"last-phase",
+			fmt.Sprintf(`expected final phase to be "%s"; is "%s"`, s.LastPhase, lastPhase),
+			fmt.Sprintf(`move (or add) the option "%s" to the end of the field "%s", or set -last-phase="%s"`, s.LastPhase, s.Fields.Phase, lastPhase),
+		))
 	}
-	if phaseOptions[len(phaseOptions)-1].Name != s.LastPhase {
-		return fmt.Errorf(`expected final phase to be "%s"; is "%s"`, s.LastPhase, phaseOptions[len(phaseOptions)-1].Name)
+	// Maintenance windows are moved to the in-progress phase, so it has to exist
+	if len(phaseOptions) > 0 && s.Maintenance.ImpactType != "" && s.Maintenance.InProgressPhase != "" {
+		found := false
+		for _, option := range phaseOptions {
+			if option.Name == s.Maintenance.InProgressPhase {
+				found = true
+			}
+		}
+		if !found {
+			problems = append(problems, configurationProblem(
+				"maintenance-phase",
+				fmt.Sprintf(`expected maintenance in-progress phase "%s" to be an option of "%s"; is not`, s.Maintenance.InProgressPhase, s.Fields.Phase),
+				fmt.Sprintf(`add the option "%s" to the field "%s", or set -maintenance.phase.in-progress to one of its options`, s.Maintenance.InProgressPhase, s.Fields.Phase),
+			))
+		}
 	}
-	// Check "Impact Type" field
-	impactTypeOptions := query.Node.ProjectV2.ImpactTypeField.ProjectV2SingleSelectField.Options
+	// Check impact type field against the configured impact types
+	impactTypeOptions := project.ImpactTypeField.ProjectV2SingleSelectField.Options
 	if len(impactTypeOptions) == 0 {
-		return fmt.Errorf(`expected to have impact types encoded as fields of "Impact Type"; not having any`)
-	}
-	// Check "Began At" field
-	if query.Node.ProjectV2.BeganAtField.ProjectV2Field.DataType != "TEXT" {
-		return fmt.Errorf(`expected field "Began At" to be "TEXT"; is "%s"`, query.Node.ProjectV2.BeganAtField.ProjectV2Field.DataType)
+		problems = append(problems, configurationProblem(
+			"impact-types",
+			fmt.Sprintf(`expected to have impact types encoded as fields of "%s"; not having any`, s.Fields.ImpactType),
+			fmt.Sprintf(`create a single select field "%s" with the options "%s"`, s.Fields.ImpactType, strings.Join(s.ImpactTypes, `", "`)),
+		))
+	} else {
+		options := map[string]bool{}
+		for _, option := range impactTypeOptions {
+			options[option.Name] = true
+		}
+		for _, impactType := range s.ImpactTypes {
+			if !options[impactType] {
+				problems = append(problems, configurationProblem(
+					"impact-types",
+					fmt.Sprintf(`expected impact type "%s" to be an option of "%s"; is not`, impactType, s.Fields.ImpactType),
+					fmt.Sprintf(`add the option "%s" to the field "%s", or remove it from -impacttypes`, impactType, s.Fields.ImpactType),
+				))
+			}
+		}
+		if s.Maintenance.ImpactType != "" && !options[s.Maintenance.ImpactType] {
+			problems = append(problems, configurationProblem(
+				"impact-types",
+				fmt.Sprintf(`expected maintenance impact type "%s" to be an option of "%s"; is not`, s.Maintenance.ImpactType, s.Fields.ImpactType),
+				fmt.Sprintf(`add the option "%s" to the field "%s", or set -maintenance.impacttype="" to disable maintenance windows`, s.Maintenance.ImpactType, s.Fields.ImpactType),
+			))
+		}
+		for _, option := range impactTypeOptions {
+			if s.impactTypeSeverity(option.Name) == 0 && option.Name != s.Maintenance.ImpactType {
+				problems = append(problems, configurationProblem(
+					"impact-types",
+					fmt.Sprintf(`expected option "%s" of "%s" to be a configured impact type; is not`, option.Name, s.Fields.ImpactType),
+					fmt.Sprintf(`add "%s" to -impacttypes at the position of its severity, or remove the option`, option.Name),
+				))
+			}
+		}
 	}
-	// Check "Ended At" field
-	if query.Node.ProjectV2.EndedAtField.ProjectV2Field.DataType != "TEXT" {
-		return fmt.Errorf(`expected field "Began At" to be "TEXT"; is "%s"`, query.Node.ProjectV2.EndedAtField.ProjectV2Field.DataType)
+	// Check began at and ended at fields, which may be accompanied by time of day fields
+	for _, field := range []struct {
+		check     string
+		name      string
+		dataType  string
+		dataTypes []string
+	}{
+		{"began-at", s.Fields.BeganAt, project.BeganAtField.ProjectV2Field.DataType, []string{"TEXT", "DATE"}},
+		{"ended-at", s.Fields.EndedAt, project.EndedAtField.ProjectV2Field.DataType, []string{"TEXT", "DATE"}},
+		{"began-at-time", s.Fields.BeganAtTime, project.BeganAtTimeField.ProjectV2Field.DataType, []string{"TEXT"}},
+		{"ended-at-time", s.Fields.EndedAtTime, project.EndedAtTimeField.ProjectV2Field.DataType, []string{"TEXT"}},
+	} {
+		if field.name == "" {
+			continue
+		}
+		if field.dataType == "" {
+			problems = append(problems, configurationProblem(
+				field.check,
+				fmt.Sprintf(`expected field "%s" to exist; does not`, field.name),
+				fmt.Sprintf(`create a text field "%s"`, field.name),
+			))
+		} else if !contains(field.dataTypes, field.dataType) {
+			problems = append(problems, configurationProblem(
+				field.check,
+				fmt.Sprintf(`expected field "%s" to be "%s"; is "%s"`, field.name, strings.Join(field.dataTypes, `" or "`), field.dataType),
+				fmt.Sprintf(`replace the field "%s" with a text field of the same name`, field.name),
+			))
+		}
 	}
-	return nil
-}
\ No newline at end of file
+	return problems, nil
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEnsureProjectConfigurationReportsAllProblems(t *testing.T) {
+	s := &ServerImplementation{
+		GithubV4Client: fakeGithub(t, func(query string, variables map[string]interface{}) string {
+			if strings.Contains(query, "repositories(") {
+				return `{"data":{"node":{"repositories":{"nodes":[],"pageInfo":{"hasNextPage":false}}}}}`
+			}
+			return `{"data":{"node":{
+				"status":{"options":[{"name":"Investigating"},{"name":"Resolved"}]},
+				"impacttype":{"options":[{"name":"outage"},{"name":"unknown"}]},
+				"beganat":{"dataType":"TEXT"},
+				"endedat":{"dataType":"NUMBER"}}}}`
+		}),
+		ProjectID:       "project",
+		Fields:          DefaultFieldNames,
+		ComponentPrefix: DefaultComponentPrefix,
+		LastPhase:       "Done",
+		ImpactTypes:     []string{"degraded", "outage"},
+	}
+	err := s.EnsureProjectConfiguration()
+	var configurationError *ConfigurationError
+	if !errors.As(err, &configurationError) {
+		t.Fatalf("expected a configuration error; got %v", err)
+	}
+	checks := []string{}
+	for _, problem := range configurationError.Problems {
+		checks = append(checks, problem.Check)
+		if problem.Hint == nil || *problem.Hint == "" {
+			t.Errorf("expected a hint for %+v", problem)
+		}
+	}
+	expected := []string{"components", "last-phase", "impact-types", "impact-types", "ended-at"}
+	if !reflect.DeepEqual(checks, expected) {
+		t.Errorf("expected the checks %q to fail; got %q", expected, checks)
+	}
+	endedAt := configurationError.Problems[len(configurationError.Problems)-1]
+	if !strings.Contains(endedAt.Message, `"Ended At"`) || !strings.Contains(*endedAt.Hint, `"Ended At"`) || strings.Contains(endedAt.Message, "Began At") {
+		t.Errorf("expected the problem to name the ended at field; got %+v", endedAt)
+	}
+	if message := err.Error(); !strings.HasPrefix(message, "found 5 problem(s) with the project configuration:\n- components: ") || !strings.Contains(message, "(hint: ") {
+		t.Errorf("unexpected error message %q", message)
+	}
+}
+
+func TestProjectConfigurationProblemsWithoutProject(t *testing.T) {
+	s := &ServerImplementation{ProjectOwner: "owner", ProjectNumber: 3}
+	problems, err := s.ProjectConfigurationProblems(context.Background())
+	if err != nil || len(problems) != 1 || problems[0].Check != "project" {
+		t.Errorf("expected a single project problem; got %+v, %v", problems, err)
+	}
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/joshmue/scs-status-page-openapi/pkg/api"
+	"github.com/labstack/echo/v4"
+)
+
+// Snapshot is the state of all components and incidents at a point in time.
+type Snapshot struct {
+	// Version increases whenever the content of a snapshot differs from the previous one
+	Version           uint64
+	TakenAt           time.Time
+	Components        []api.Component
+	Incidents         []api.Incident
+	ComponentStatuses map[string]string
+}
+
+// LatestSnapshot returns the most recent snapshot, or nil if none was taken yet.
+func (s *ServerImplementation) LatestSnapshot() *Snapshot {
+	s.snapshotMutex.RLock()
+	defer s.snapshotMutex.RUnlock()
+	return s.snapshot
+}
+
+func (s *ServerImplementation) takeSnapshot(ctx context.Context, logger echo.Logger) (*Snapshot, error) {
+	components, err := s.Components(ctx)
+	if err != nil {
+		return nil, err
+	}
+	incidents, err := s.Incidents(ctx, logger)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &Snapshot{
+		TakenAt:           now,
+		Components:        components,
+		Incidents:         incidents,
+		ComponentStatuses: s.ComponentStatuses(components, incidents, now),
+	}, nil
+}
+
+// RunSnapshots periodically takes snapshots and publishes the changes between
+// successive ones as events. It blocks until ctx is done.
+func (s *ServerImplementation) RunSnapshots(ctx context.Context, logger echo.Logger, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.updateSnapshot(ctx, logger); err != nil {
+			logger.Error(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *ServerImplementation) updateSnapshot(ctx context.Context, logger echo.Logger) error {
+	next, err := s.takeSnapshot(ctx, logger)
+	if err != nil {
+		return err
+	}
+	previous := s.LatestSnapshot()
+	events := []Event{}
+	if previous != nil {
+		next.Version = previous.Version
+		events = s.diffSnapshots(previous, next)
+		if len(events) > 0 || !reflect.DeepEqual(previous.Components, next.Components) {
+			next.Version++
+		}
+	}
+	s.snapshotMutex.Lock()
+	s.snapshot = next
+	s.snapshotMutex.Unlock()
+	s.events.publish(events)
+	return nil
+}
+
+// diffSnapshots derives events from the changes between two snapshots.
+func (s *ServerImplementation) diffSnapshots(previous *Snapshot, next *Snapshot) []Event {
+	events := []Event{}
+	previousIncidents := map[string]api.Incident{}
+	for _, incident := range previous.Incidents {
+		previousIncidents[incident.Id] = incident
+	}
+	for i := range next.Incidents {
+		incident := &next.Incidents[i]
+		before, existed := previousIncidents[incident.Id]
+		switch {
+		case !existed:
+			events = append(events, Event{Type: EventIncidentCreated, Incident: incident})
+		case reflect.DeepEqual(before, *incident):
+		case before.Phase != s.LastPhase && incident.Phase == s.LastPhase:
+			events = append(events, Event{Type: EventIncidentResolved, Incident: incident, PreviousPhase: before.Phase, PhaseChanged: true})
+		case before.Phase != incident.Phase:
+			events = append(events, Event{Type: EventIncidentUpdated, Incident: incident, PreviousPhase: before.Phase, PhaseChanged: true})
+		default:
+			events = append(events, Event{Type: EventIncidentUpdated, Incident: incident})
+		}
+	}
+	for _, component := range next.Components {
+		before, existed := previous.ComponentStatuses[component.Id]
+		if !existed {
+			before = ComponentOperational
+		}
+		status := next.ComponentStatuses[component.Id]
+		if status != before {
+			events = append(events, Event{
+				Type:           EventComponentStatusChanged,
+				ComponentId:    component.Id,
+				Status:         status,
+				PreviousStatus: before,
+			})
+		}
+	}
+	return events
+}
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+)
+
+// loadState reads state persisted by saveState into v. If path is empty or
+// does not exist yet, v is left untouched.
+func loadState(path string, v interface{}) error {
+	if path == "" {
+		return nil
+	}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(content, v)
+}
+
+// saveState persists v as JSON to path, unless path is empty.
+func saveState(path string, v interface{}) error {
+	if path == "" {
+		return nil
+	}
+	content, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write atomically, so a crash does not lose all state
+	temporary := path + ".tmp"
+	if err := os.WriteFile(temporary, content, 0600); err != nil {
+		return err
+	}
+	return os.Rename(temporary, path)
+}
@@ -0,0 +1,447 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joshmue/scs-status-page-openapi/pkg/api"
+	"github.com/labstack/echo/v4"
+)
+
+// Component statuses as used by the Statuspage.io v2 API, ordered by severity.
+const (
+	StatuspageOperational         = "operational"
+	StatuspageUnderMaintenance    = "under_maintenance"
+	StatuspageDegradedPerformance = "degraded_performance"
+	StatuspagePartialOutage       = "partial_outage"
+	StatuspageMajorOutage         = "major_outage"
+)
+
+var statuspageComponentStatusSeverity = map[string]int{
+	StatuspageOperational:         0,
+	StatuspageUnderMaintenance:    1,
+	StatuspageDegradedPerformance: 2,
+	StatuspagePartialOutage:       3,
+	StatuspageMajorOutage:         4,
+}
+
+// Indicators and descriptions corresponding to the component statuses above.
+var statuspageIndicators = []string{"none", "maintenance", "minor", "major", "critical"}
+var statuspageDescriptions = []string{
+	"All Systems Operational",
+	"Service Under Maintenance",
+	"Minor Service Outage",
+	"Partial System Outage",
+	"Major Service Outage",
+}
+
+// Statuspage statuses of scheduled maintenances.
+var statuspageMaintenanceStatus = map[api.MaintenanceStatus]string{
+	api.Scheduled:  "scheduled",
+	api.InProgress: "in_progress",
+	api.Completed:  "completed",
+}
+
+// StatuspageConfig configures the Statuspage.io v2 compatibility layer.
+type StatuspageConfig struct {
+	// PageName and PageURL are reported as the "page" of every response.
+	PageName string
+	PageURL  string
+	// ImpactTypeStatus maps impact types to Statuspage component statuses.
+	// Unmapped impact types are reported as "partial_outage".
+	ImpactTypeStatus map[string]string
+}
+
+type statuspagePage struct {
+	Id        string    `json:"id"`
+	Name      string    `json:"name"`
+	Url       string    `json:"url"`
+	TimeZone  string    `json:"time_zone"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type statuspageStatus struct {
+	Indicator   string `json:"indicator"`
+	Description string `json:"description"`
+}
+
+type statuspageComponent struct {
+	Id                 string     `json:"id"`
+	Name               string     `json:"name"`
+	Status             string     `json:"status"`
+	CreatedAt          *time.Time `json:"created_at"`
+	UpdatedAt          *time.Time `json:"updated_at"`
+	Position           int        `json:"position"`
+	Description        *string    `json:"description"`
+	Showcase           bool       `json:"showcase"`
+	StartDate          *string    `json:"start_date"`
+	GroupId            *string    `json:"group_id"`
+	PageId             string     `json:"page_id"`
+	Group              bool       `json:"group"`
+	OnlyShowIfDegraded bool       `json:"only_show_if_degraded"`
+}
+
+type statuspageIncidentUpdate struct {
+	Id         string     `json:"id"`
+	Status     string     `json:"status"`
+	Body       string     `json:"body"`
+	IncidentId string     `json:"incident_id"`
+	CreatedAt  *time.Time `json:"created_at"`
+	UpdatedAt  *time.Time `json:"updated_at"`
+	DisplayAt  *time.Time `json:"display_at"`
+}
+
+type statuspageIncident struct {
+	Id              string                     `json:"id"`
+	Name            string                     `json:"name"`
+	Status          string                     `json:"status"`
+	CreatedAt       *time.Time                 `json:"created_at"`
+	UpdatedAt       *time.Time                 `json:"updated_at"`
+	MonitoringAt    *time.Time                 `json:"monitoring_at"`
+	ResolvedAt      *time.Time                 `json:"resolved_at"`
+	Impact          string                     `json:"impact"`
+	Shortlink       string                     `json:"shortlink"`
+	StartedAt       *time.Time                 `json:"started_at"`
+	PageId          string                     `json:"page_id"`
+	ScheduledFor    *time.Time                 `json:"scheduled_for,omitempty"`
+	ScheduledUntil  *time.Time                 `json:"scheduled_until,omitempty"`
+	IncidentUpdates []statuspageIncidentUpdate `json:"incident_updates"`
+	Components      []statuspageComponent      `json:"components"`
+}
+
+type statuspageSummary struct {
+	Page                  statuspagePage        `json:"page"`
+	Status                statuspageStatus      `json:"status"`
+	Components            []statuspageComponent `json:"components"`
+	Incidents             []statuspageIncident  `json:"incidents"`
+	ScheduledMaintenances []statuspageIncident  `json:"scheduled_maintenances"`
+}
+
+// statuspageData holds everything needed to render any of the Statuspage.io responses.
+type statuspageData struct {
+	page       statuspagePage
+	status     statuspageStatus
+	components []statuspageComponent
+	incidents  []statuspageIncident
+	unresolved []statuspageIncident
+	// maintenances and upcoming are scheduled maintenances; upcoming ones are not completed yet.
+	maintenances []statuspageIncident
+	upcoming     []statuspageIncident
+}
+
+// RegisterStatuspageHandlers adds read-only routes compatible with the Statuspage.io v2 API.
+func (s *ServerImplementation) RegisterStatuspageHandlers(router api.EchoRouter) {
+	router.GET("/api/v2/summary.json", s.GetStatuspageSummary)
+	router.GET("/api/v2/status.json", s.GetStatuspageStatus)
+	router.GET("/api/v2/components.json", s.GetStatuspageComponents)
+	router.GET("/api/v2/incidents.json", s.GetStatuspageIncidents)
+	router.GET("/api/v2/incidents/unresolved.json", s.GetStatuspageUnresolvedIncidents)
+	router.GET("/api/v2/scheduled-maintenances.json", s.GetStatuspageScheduledMaintenances)
+	router.GET("/api/v2/scheduled-maintenances/upcoming.json", s.GetStatuspageUpcomingScheduledMaintenances)
+}
+
+func (s *ServerImplementation) GetStatuspageSummary(ctx echo.Context) error {
+	data, err := s.statuspageData(ctx.Request().Context(), ctx.Logger())
+	if err != nil {
+		return upstreamError(ctx, err)
+	}
+	return ctx.JSON(200, statuspageSummary{
+		Page:                  data.page,
+		Status:                data.status,
+		Components:            data.components,
+		Incidents:             data.unresolved,
+		ScheduledMaintenances: data.upcoming,
+	})
+}
+
+func (s *ServerImplementation) GetStatuspageStatus(ctx echo.Context) error {
+	data, err := s.statuspageData(ctx.Request().Context(), ctx.Logger())
+	if err != nil {
+		return upstreamError(ctx, err)
+	}
+	return ctx.JSON(200, map[string]interface{}{
+		"page":   data.page,
+		"status": data.status,
+	})
+}
+
+func (s *ServerImplementation) GetStatuspageComponents(ctx echo.Context) error {
+	data, err := s.statuspageData(ctx.Request().Context(), ctx.Logger())
+	if err != nil {
+		return upstreamError(ctx, err)
+	}
+	return ctx.JSON(200, map[string]interface{}{
+		"page":       data.page,
+		"components": data.components,
+	})
+}
+
+func (s *ServerImplementation) GetStatuspageIncidents(ctx echo.Context) error {
+	data, err := s.statuspageData(ctx.Request().Context(), ctx.Logger())
+	if err != nil {
+		return upstreamError(ctx, err)
+	}
+	return ctx.JSON(200, map[string]interface{}{
+		"page":      data.page,
+		"incidents": data.incidents,
+	})
+}
+
+func (s *ServerImplementation) GetStatuspageUnresolvedIncidents(ctx echo.Context) error {
+	data, err := s.statuspageData(ctx.Request().Context(), ctx.Logger())
+	if err != nil {
+		return upstreamError(ctx, err)
+	}
+	return ctx.JSON(200, map[string]interface{}{
+		"page":      data.page,
+		"incidents": data.unresolved,
+	})
+}
+
+func (s *ServerImplementation) GetStatuspageScheduledMaintenances(ctx echo.Context) error {
+	data, err := s.statuspageData(ctx.Request().Context(), ctx.Logger())
+	if err != nil {
+		return upstreamError(ctx, err)
+	}
+	return ctx.JSON(200, map[string]interface{}{
+		"page":                   data.page,
+		"scheduled_maintenances": data.maintenances,
+	})
+}
+
+func (s *ServerImplementation) GetStatuspageUpcomingScheduledMaintenances(ctx echo.Context) error {
+	data, err := s.statuspageData(ctx.Request().Context(), ctx.Logger())
+	if err != nil {
+		return upstreamError(ctx, err)
+	}
+	return ctx.JSON(200, map[string]interface{}{
+		"page":                   data.page,
+		"scheduled_maintenances": data.upcoming,
+	})
+}
+
+func (s *ServerImplementation) statuspageData(ctx context.Context, logger echo.Logger) (*statuspageData, error) {
+	components, err := s.Components(ctx)
+	if err != nil {
+		return nil, err
+	}
+	incidents, err := s.Incidents(ctx, logger)
+	if err != nil {
+		return nil, err
+	}
+	phases, err := s.Phases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data := &statuspageData{
+		page: statuspagePage{
+			Id:        s.ProjectID,
+			Name:      s.Statuspage.PageName,
+			Url:       s.Statuspage.PageURL,
+			TimeZone:  "Etc/UTC",
+			UpdatedAt: time.Now().UTC(),
+		},
+		components:   []statuspageComponent{},
+		incidents:    []statuspageIncident{},
+		unresolved:   []statuspageIncident{},
+		maintenances: []statuspageIncident{},
+		upcoming:     []statuspageIncident{},
+	}
+	now := time.Now()
+
+	// Derive component status from all unresolved incidents and ongoing maintenances affecting them
+	componentStatus := map[string]string{}
+	for _, incident := range incidents {
+		if !s.IsOngoing(incident, now) {
+			continue
+		}
+		status := s.statuspageComponentStatus(incident.ImpactType)
+		if s.IsMaintenance(incident) {
+			status = StatuspageUnderMaintenance
+		}
+		for _, componentId := range incident.Affects {
+			if statuspageComponentStatusSeverity[status] > statuspageComponentStatusSeverity[componentStatus[componentId]] {
+				componentStatus[componentId] = status
+			}
+		}
+	}
+	worst := 0
+	componentsById := map[string]statuspageComponent{}
+	for position, component := range components {
+		status, ok := componentStatus[component.Id]
+		if !ok {
+			status = StatuspageOperational
+		}
+		if statuspageComponentStatusSeverity[status] > worst {
+			worst = statuspageComponentStatusSeverity[status]
+		}
+		converted := statuspageComponent{
+			Id:       component.Id,
+			Name:     component.DisplayName,
+			Status:   status,
+			Position: position + 1,
+			PageId:   s.ProjectID,
+		}
+		componentsById[component.Id] = converted
+		data.components = append(data.components, converted)
+	}
+	data.status = statuspageStatus{
+		Indicator:   statuspageIndicators[worst],
+		Description: statuspageDescriptions[worst],
+	}
+
+	for _, incident := range incidents {
+		if s.IsMaintenance(incident) {
+			converted := s.toStatuspageMaintenance(s.ToMaintenance(incident, now), componentsById)
+			data.maintenances = append(data.maintenances, converted)
+			if converted.Status != "completed" {
+				data.upcoming = append(data.upcoming, converted)
+			}
+			continue
+		}
+		converted := s.toStatuspageIncident(incident, phases, componentsById)
+		data.incidents = append(data.incidents, converted)
+		if converted.Status != "resolved" {
+			data.unresolved = append(data.unresolved, converted)
+		}
+	}
+	return data, nil
+}
+
+// ValidateStatuspage checks that impact types are mapped to component statuses known to Statuspage.
+func (s *ServerImplementation) ValidateStatuspage() error {
+	impactTypes := []string{}
+	for impactType := range s.Statuspage.ImpactTypeStatus {
+		impactTypes = append(impactTypes, impactType)
+	}
+	sort.Strings(impactTypes)
+	for _, impactType := range impactTypes {
+		status := s.Statuspage.ImpactTypeStatus[impactType]
+		if _, ok := statuspageComponentStatusSeverity[status]; !ok {
+			return fmt.Errorf(`expected impact type "%s" to be mapped to a Statuspage component status; "%s" is none of "%s"`, impactType, status, strings.Join(statuspageComponentStatuses(), `", "`))
+		}
+	}
+	return nil
+}
+
+// statuspageComponentStatuses returns the Statuspage component statuses ordered by severity.
+func statuspageComponentStatuses() []string {
+	statuses := make([]string, len(statuspageComponentStatusSeverity))
+	for status, severity := range statuspageComponentStatusSeverity {
+		statuses[severity] = status
+	}
+	return statuses
+}
+
+func (s *ServerImplementation) statuspageComponentStatus(impactType api.IncidentImpactType) string {
+	if status, ok := s.Statuspage.ImpactTypeStatus[impactType]; ok {
+		return status
+	}
+	return StatuspagePartialOutage
+}
+
+// statuspageIncidentStatus maps a phase to one of the Statuspage incident statuses
+// by its position in the ordered list of phases: The last phase is "resolved",
+// the one before is "monitoring", the first one is "investigating" and everything
+// in between is "identified".
+func (s *ServerImplementation) statuspageIncidentStatus(phase api.IncidentPhase, phases []api.IncidentPhase) string {
+	if phase == s.LastPhase {
+		return "resolved"
+	}
+	position := -1
+	for i := range phases {
+		if phases[i] == phase {
+			position = i
+		}
+	}
+	switch {
+	case position <= 0:
+		return "investigating"
+	case position == len(phases)-2:
+		return "monitoring"
+	default:
+		return "identified"
+	}
+}
+
+func (s *ServerImplementation) toStatuspageIncident(incident api.Incident, phases []api.IncidentPhase, componentsById map[string]statuspageComponent) statuspageIncident {
+	status := s.statuspageIncidentStatus(incident.Phase, phases)
+	updatedAt := incident.BeganAt
+	if incident.EndedAt != nil {
+		updatedAt = incident.EndedAt
+	}
+	converted := statuspageIncident{
+		Id:         incident.Id,
+		Name:       incident.Title,
+		Status:     status,
+		CreatedAt:  incident.BeganAt,
+		UpdatedAt:  updatedAt,
+		Impact:     statuspageIndicators[statuspageComponentStatusSeverity[s.statuspageComponentStatus(incident.ImpactType)]],
+		Shortlink:  s.Statuspage.PageURL,
+		StartedAt:  incident.BeganAt,
+		PageId:     s.ProjectID,
+		Components: []statuspageComponent{},
+		// There is no history of phase changes, so the current phase is the only update
+		IncidentUpdates: []statuspageIncidentUpdate{{
+			Id:         incident.Id + "-" + incident.Phase,
+			Status:     status,
+			Body:       incident.Phase,
+			IncidentId: incident.Id,
+			CreatedAt:  updatedAt,
+			UpdatedAt:  updatedAt,
+			DisplayAt:  updatedAt,
+		}},
+	}
+	if status == "resolved" {
+		converted.ResolvedAt = incident.EndedAt
+	}
+	if status == "monitoring" {
+		converted.MonitoringAt = updatedAt
+	}
+	for _, componentId := range incident.Affects {
+		if component, ok := componentsById[componentId]; ok {
+			converted.Components = append(converted.Components, component)
+		}
+	}
+	return converted
+}
+
+func (s *ServerImplementation) toStatuspageMaintenance(maintenance api.Maintenance, componentsById map[string]statuspageComponent) statuspageIncident {
+	status := statuspageMaintenanceStatus[maintenance.Status]
+	converted := statuspageIncident{
+		Id:             maintenance.Id,
+		Name:           maintenance.Title,
+		Status:         status,
+		CreatedAt:      &maintenance.PlannedStart,
+		UpdatedAt:      &maintenance.PlannedStart,
+		Impact:         "maintenance",
+		Shortlink:      s.Statuspage.PageURL,
+		StartedAt:      &maintenance.PlannedStart,
+		PageId:         s.ProjectID,
+		ScheduledFor:   &maintenance.PlannedStart,
+		ScheduledUntil: &maintenance.PlannedEnd,
+		Components:     []statuspageComponent{},
+		IncidentUpdates: []statuspageIncidentUpdate{{
+			Id:         maintenance.Id + "-" + string(maintenance.Status),
+			Status:     status,
+			Body:       maintenance.Phase,
+			IncidentId: maintenance.Id,
+			CreatedAt:  &maintenance.PlannedStart,
+			UpdatedAt:  &maintenance.PlannedStart,
+			DisplayAt:  &maintenance.PlannedStart,
+		}},
+	}
+	if maintenance.Status == api.Completed {
+		converted.UpdatedAt = &maintenance.PlannedEnd
+		converted.ResolvedAt = &maintenance.PlannedEnd
+	}
+	for _, componentId := range maintenance.Affects {
+		if component, ok := componentsById[componentId]; ok {
+			converted.Components = append(converted.Components, component)
+		}
+	}
+	return converted
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"testing"
+)
+
+func TestValidateStatuspage(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping map[string]string
+		fails   bool
+	}{
+		{"no mapping", nil, false},
+		{"known statuses", map[string]string{"performance-degration": "degraded_performance", "connectivity-issues": "major_outage"}, false},
+		{"unknown status", map[string]string{"connectivity-issues": "major-outage"}, true},
+		{"empty status", map[string]string{"connectivity-issues": ""}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := &ServerImplementation{Statuspage: StatuspageConfig{ImpactTypeStatus: test.mapping}}
+			if err := s.ValidateStatuspage(); (err != nil) != test.fails {
+				t.Errorf("expected failure %t; got %v", test.fails, err)
+			}
+		})
+	}
+}
+
+func TestStatuspageIncidentStatus(t *testing.T) {
+	s := &ServerImplementation{LastPhase: "Done"}
+	phases := []string{"Investigating", "Identified", "Fixing", "Monitoring", "Done"}
+	for phase, expected := range map[string]string{
+		"Investigating": "investigating",
+		"Identified":    "identified",
+		"Fixing":        "identified",
+		"Monitoring":    "monitoring",
+		"Done":          "resolved",
+	} {
+		if status := s.statuspageIncidentStatus(phase, phases); status != expected {
+			t.Errorf("expected phase %q to be %q; got %q", phase, expected, status)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/joshmue/scs-status-page-openapi/pkg/api"
+)
+
+// timestampLayouts are the accepted layouts of timestamps, tried in order.
+// Timestamps without offset are interpreted in the configured time zone.
+var timestampLayouts = append([]string{
+	time.RFC3339,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04Z07:00",
+	"2006-01-02 15:04:05 Z07:00",
+	"2006-01-02 15:04 Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02 3:04 PM",
+	"2006-01-02 3:04PM",
+	time.RFC1123Z,
+	time.RFC1123,
+	"02.01.2006 15:04:05",
+	"02.01.2006 15:04",
+}, dateLayouts...)
+
+// dateLayouts are the accepted layouts of bare dates.
+var dateLayouts = []string{
+	"2006-01-02",
+	"02.01.2006",
+}
+
+// ParseTimeOrNil parses a timestamp like ParseTimestamp, interpreting timestamps without offset as UTC.
+//
+// Deprecated: Use ParseTimestamp.
+func ParseTimeOrNil(timeString string) (*time.Time, error) {
+	return ParseTimestamp(timeString, time.UTC)
+}
+
+// ParseTimestamp parses timestamps leniently, accepting RFC 3339 as well as common variants
+// like "2006-01-02 15:04" and bare dates, which refer to the start of the day. Timestamps
+// without offset are interpreted in location. Empty values result in nil.
+func ParseTimestamp(value string, location *time.Location) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	for _, layout := range timestampLayouts {
+		if timestamp, err := time.ParseInLocation(layout, value, location); err == nil {
+			return &timestamp, nil
+		}
+	}
+	return nil, fmt.Errorf(`cannot parse "%s" as timestamp`, value)
+}
+
+// projectItemTimestampValue is the value of a text or date field holding (a part of) a timestamp.
+type projectItemTimestampValue struct {
+	ProjectV2ItemFieldTextValue struct {
+		Text string
+	} `graphql:"... on ProjectV2ItemFieldTextValue"`
+	ProjectV2ItemFieldDateValue struct {
+		Date string
+	} `graphql:"... on ProjectV2ItemFieldDateValue"`
+}
+
+func (v *projectItemTimestampValue) String() string {
+	if v.ProjectV2ItemFieldDateValue.Date != "" {
+		return v.ProjectV2ItemFieldDateValue.Date
+	}
+	return v.ProjectV2ItemFieldTextValue.Text
+}
+
+// isDate returns whether value is a bare date without time of day.
+func isDate(value string) bool {
+	for _, layout := range dateLayouts {
+		if _, err := time.Parse(layout, strings.TrimSpace(value)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// itemTimestamp interprets the value of a timestamp field, combined with the value of its time of
+// day field, if any. Bare dates refer to the start of the day or, if endOfDay is set, to the next
+// midnight, so that they cover the whole day. Unparseable values result in nil and a warning.
+func (s *ServerImplementation) itemTimestamp(value projectItemTimestampValue, timeValue projectItemTimestampValue, field string, timeField string, endOfDay bool) (*time.Time, *api.DataQualityWarning) {
+	combined := strings.TrimSpace(value.String())
+	if timeOfDay := strings.TrimSpace(timeValue.String()); timeOfDay != "" {
+		if combined == "" {
+			return nil, &api.DataQualityWarning{
+				Field:   timeField,
+				Value:   timeOfDay,
+				Message: fmt.Sprintf(`expected field "%s" to hold a date along with the time of day`, field),
+			}
+		}
+		combined += " " + timeOfDay
+		field += ", " + timeField
+	}
+	timestamp, err := ParseTimestamp(combined, s.TimestampTimeZone)
+	if err != nil {
+		return nil, &api.DataQualityWarning{
+			Field:   field,
+			Value:   combined,
+			Message: err.Error(),
+		}
+	}
+	if timestamp != nil && endOfDay && isDate(combined) {
+		endedAt := timestamp.AddDate(0, 0, 1)
+		timestamp = &endedAt
+	}
+	return timestamp, nil
+}
+
+// setItemTimestamp sets a text or date field of a project item to a timestamp. If timeField
+// is set, the field only receives the date and the time of day field the rest. Date fields
+// without time of day field receive the date the timestamp is read back as by itemTimestamp,
+// which is the last day it covers if endOfDay is set.
+func (s *ServerImplementation) setItemTimestamp(ctx context.Context, itemId string, field string, timeField string, timestamp time.Time, endOfDay bool) error {
+	if timeField == "" {
+		projectField, err := s.projectField(ctx, field)
+		if err != nil {
+			return err
+		}
+		if projectField.DataType == "DATE" && endOfDay {
+			// A bare date is read back as the following midnight, so a timestamp at
+			// midnight is written as the date before
+			timestamp = timestamp.Add(-time.Nanosecond)
+		}
+		return s.setItemField(ctx, itemId, projectField, timestamp.Format(time.RFC3339))
+	}
+	timestamp = timestamp.In(s.TimestampTimeZone)
+	if err := s.setItemFieldValue(ctx, itemId, field, timestamp.Format("2006-01-02")); err != nil {
+		return err
+	}
+	return s.setItemFieldValue(ctx, itemId, timeField, timestamp.Format("15:04:05Z07:00"))
+}
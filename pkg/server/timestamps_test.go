@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestItemTimestampEndOfDay(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+	s := &ServerImplementation{TimestampTimeZone: berlin}
+	date := func(value string) projectItemTimestampValue {
+		var v projectItemTimestampValue
+		v.ProjectV2ItemFieldDateValue.Date = value
+		return v
+	}
+	text := func(value string) projectItemTimestampValue {
+		var v projectItemTimestampValue
+		v.ProjectV2ItemFieldTextValue.Text = value
+		return v
+	}
+	tests := []struct {
+		name      string
+		value     projectItemTimestampValue
+		timeValue projectItemTimestampValue
+		endOfDay  bool
+		expected  time.Time
+	}{
+		{"start of day", date("2024-03-30"), text(""), false, time.Date(2024, 3, 30, 0, 0, 0, 0, berlin)},
+		{"end of day", date("2024-03-30"), text(""), true, time.Date(2024, 3, 31, 0, 0, 0, 0, berlin)},
+		{"end of day across DST change", date("2024-03-31"), text(""), true, time.Date(2024, 4, 1, 0, 0, 0, 0, berlin)},
+		{"end of day with time of day", date("2024-03-30"), text("12:30"), true, time.Date(2024, 3, 30, 12, 30, 0, 0, berlin)},
+		{"end of day with timestamp", text("2024-03-30T12:30:00Z"), text(""), true, time.Date(2024, 3, 30, 12, 30, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			timestamp, warning := s.itemTimestamp(test.value, test.timeValue, "Ended At", "Ended At Time", test.endOfDay)
+			if warning != nil {
+				t.Fatal(warning.Message)
+			}
+			if timestamp == nil || !timestamp.Equal(test.expected) {
+				t.Errorf("expected %s; got %v", test.expected, timestamp)
+			}
+		})
+	}
+}
+
+func TestItemTimestampRoundTrip(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+	tests := []struct {
+		name      string
+		dataType  string
+		timestamp time.Time
+		endOfDay  bool
+		expected  time.Time
+	}{
+		{"text", "TEXT", time.Date(2024, 3, 30, 12, 30, 0, 0, berlin), true, time.Date(2024, 3, 30, 12, 30, 0, 0, berlin)},
+		{"date at start of day", "DATE", time.Date(2024, 3, 30, 12, 30, 0, 0, berlin), false, time.Date(2024, 3, 30, 0, 0, 0, 0, berlin)},
+		{"date at end of day", "DATE", time.Date(2024, 3, 30, 12, 30, 0, 0, berlin), true, time.Date(2024, 3, 31, 0, 0, 0, 0, berlin)},
+		{"date at midnight", "DATE", time.Date(2024, 3, 31, 0, 0, 0, 0, berlin), true, time.Date(2024, 3, 31, 0, 0, 0, 0, berlin)},
+		{"date at midnight in UTC", "DATE", time.Date(2024, 3, 30, 23, 0, 0, 0, time.UTC), true, time.Date(2024, 3, 31, 0, 0, 0, 0, berlin)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var written projectItemTimestampValue
+			s := &ServerImplementation{
+				GithubV4Client: fakeGithub(t, func(query string, variables map[string]interface{}) string {
+					if strings.Contains(query, "$fieldname") {
+						return `{"data":{"node":{"field":{"id":"field","name":"Ended At","dataType":"` + test.dataType + `"}}}}`
+					}
+					value := variables["input"].(map[string]interface{})["value"].(map[string]interface{})
+					if date, ok := value["date"].(string); ok {
+						written.ProjectV2ItemFieldDateValue.Date = date[:len("2006-01-02")]
+					} else {
+						written.ProjectV2ItemFieldTextValue.Text = value["text"].(string)
+					}
+					return `{"data":{"updateProjectV2ItemFieldValue":{"projectV2Item":{"id":"item"}}}}`
+				}),
+				ProjectID:         "project",
+				TimestampTimeZone: berlin,
+			}
+			if err := s.setItemTimestamp(context.Background(), "item", "Ended At", "", test.timestamp, test.endOfDay); err != nil {
+				t.Fatal(err)
+			}
+			timestamp, warning := s.itemTimestamp(written, projectItemTimestampValue{}, "Ended At", "", test.endOfDay)
+			if warning != nil {
+				t.Fatal(warning.Message)
+			}
+			if timestamp == nil || !timestamp.Equal(test.expected) {
+				t.Errorf("expected %s to be read back as %s; got %v from %+v", test.timestamp, test.expected, timestamp, written)
+			}
+		})
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+	tests := []struct {
+		value    string
+		expected *time.Time
+		fails    bool
+	}{
+		{value: ""},
+		{value: "  "},
+		{value: "2024-01-02T03:04:05Z", expected: timestamp(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))},
+		{value: "2024-01-02T03:04:05+01:00", expected: timestamp(time.Date(2024, 1, 2, 2, 4, 5, 0, time.UTC))},
+		{value: "2024-01-02T03:04Z", expected: timestamp(time.Date(2024, 1, 2, 3, 4, 0, 0, time.UTC))},
+		{value: "2024-01-02 03:04:05 +02:00", expected: timestamp(time.Date(2024, 1, 2, 1, 4, 5, 0, time.UTC))},
+		{value: " 2024-01-02 03:04 ", expected: timestamp(time.Date(2024, 1, 2, 3, 4, 0, 0, berlin))},
+		{value: "2024-07-02T03:04:05", expected: timestamp(time.Date(2024, 7, 2, 3, 4, 5, 0, berlin))},
+		{value: "2024-01-02 3:04 PM", expected: timestamp(time.Date(2024, 1, 2, 15, 4, 0, 0, berlin))},
+		{value: "Tue, 02 Jan 2024 03:04:05 +0000", expected: timestamp(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))},
+		{value: "02.01.2024 03:04", expected: timestamp(time.Date(2024, 1, 2, 3, 4, 0, 0, berlin))},
+		{value: "2024-01-02", expected: timestamp(time.Date(2024, 1, 2, 0, 0, 0, 0, berlin))},
+		{value: "02.01.2024", expected: timestamp(time.Date(2024, 1, 2, 0, 0, 0, 0, berlin))},
+		{value: "yesterday", fails: true},
+		{value: "2024-13-02", fails: true},
+	}
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			parsed, err := ParseTimestamp(test.value, berlin)
+			if test.fails {
+				if err == nil {
+					t.Errorf("expected an error; got %v", parsed)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (parsed == nil) != (test.expected == nil) || (parsed != nil && !parsed.Equal(*test.expected)) {
+				t.Errorf("expected %v; got %v", test.expected, parsed)
+			}
+		})
+	}
+}
+
+func TestParseTimeOrNil(t *testing.T) {
+	parsed, err := ParseTimeOrNil("2024-01-02T03:04:05")
+	if err != nil || parsed == nil || !parsed.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("expected timestamps without offset in UTC; got %v, %v", parsed, err)
+	}
+}
+
+func timestamp(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,362 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/joshmue/scs-status-page-openapi/pkg/api"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// webhookQueueSize is the number of deliveries waiting to be sent.
+	webhookQueueSize = 1000
+	// webhookWorkers is the number of deliveries sent concurrently.
+	webhookWorkers = 4
+	// webhookMaxAttempts is the number of attempts per delivery before giving up.
+	webhookMaxAttempts = 6
+	// webhookInitialBackoff is the delay before the first retry; it doubles with every retry.
+	webhookInitialBackoff = 5 * time.Second
+	// webhookDeliveryLogSize is the number of delivery attempts kept per subscription.
+	webhookDeliveryLogSize = 100
+	webhookTimeout         = 10 * time.Second
+)
+
+// WebhookConfig configures outbound webhook notifications.
+type WebhookConfig struct {
+	// StateFile persists subscriptions across restarts, if set.
+	StateFile string
+}
+
+// NotificationType returns the type of notification to send for an event, if any.
+// Notifications are sent when incidents open, change phase or reach the last phase.
+func NotificationType(event Event) (api.WebhookEventType, bool) {
+	switch {
+	case event.Incident == nil:
+		return "", false
+	case event.Type == EventIncidentCreated:
+		return api.IncidentCreated, true
+	case event.Type == EventIncidentResolved:
+		return api.IncidentResolved, true
+	case event.Type == EventIncidentUpdated && event.PhaseChanged:
+		return api.IncidentPhaseChanged, true
+	}
+	return "", false
+}
+
+type webhookSubscription struct {
+	api.WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+func (w *webhookSubscription) matches(eventType api.WebhookEventType, incident api.Incident) bool {
+	if len(w.Events) > 0 {
+		found := false
+		for _, subscribed := range w.Events {
+			found = found || subscribed == eventType
+		}
+		if !found {
+			return false
+		}
+	}
+	return incidentAffectsAny(incident, w.Components)
+}
+
+// webhookDelivery is a single event to be delivered to a subscription.
+type webhookDelivery struct {
+	Id             string
+	SubscriptionId string
+	Event          Event
+	EventType      api.WebhookEventType
+	Payload        []byte
+	Attempt        int
+}
+
+// webhookStore holds subscriptions, their delivery logs and the delivery queue.
+type webhookStore struct {
+	mutex         sync.Mutex
+	subscriptions map[string]*webhookSubscription
+	deliveries    map[string][]api.WebhookDelivery
+	queue         chan webhookDelivery
+}
+
+// newId returns a random ID for subscriptions and deliveries.
+func newId() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(id)
+}
+
+// signPayload returns the value of the "X-SCS-Signature-256" header for the payload.
+func signPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *ServerImplementation) loadWebhooks() error {
+	s.webhookStore.mutex.Lock()
+	defer s.webhookStore.mutex.Unlock()
+	s.webhookStore.subscriptions = map[string]*webhookSubscription{}
+	s.webhookStore.deliveries = map[string][]api.WebhookDelivery{}
+	return loadState(s.Webhooks.StateFile, &s.webhookStore.subscriptions)
+}
+
+// saveWebhooks persists subscriptions. The caller must hold the mutex.
+func (s *ServerImplementation) saveWebhooks() error {
+	return saveState(s.Webhooks.StateFile, s.webhookStore.subscriptions)
+}
+
+// RunWebhooks loads subscriptions and delivers notifications derived from events.
+// It blocks until ctx is done.
+func (s *ServerImplementation) RunWebhooks(ctx context.Context, logger echo.Logger) error {
+	if err := s.loadWebhooks(); err != nil {
+		return err
+	}
+	s.webhookStore.mutex.Lock()
+	s.webhookStore.queue = make(chan webhookDelivery, webhookQueueSize)
+	s.webhookStore.mutex.Unlock()
+	s.events.listen(func(event Event) {
+		s.enqueueWebhooks(logger, event)
+	})
+	client := &http.Client{Timeout: webhookTimeout}
+	var wg sync.WaitGroup
+	for i := 0; i < webhookWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case delivery := <-s.webhookStore.queue:
+					s.deliverWebhook(ctx, logger, client, delivery)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+func (s *ServerImplementation) enqueueWebhooks(logger echo.Logger, event Event) {
+	eventType, ok := NotificationType(event)
+	if !ok {
+		return
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+	s.webhookStore.mutex.Lock()
+	defer s.webhookStore.mutex.Unlock()
+	for id, subscription := range s.webhookStore.subscriptions {
+		if !subscription.matches(eventType, *event.Incident) {
+			continue
+		}
+		s.enqueueWebhook(webhookDelivery{
+			Id:             newId(),
+			SubscriptionId: id,
+			Event:          event,
+			EventType:      eventType,
+			Payload:        payload,
+			Attempt:        1,
+		})
+	}
+}
+
+// enqueueWebhook queues a delivery without blocking. The caller must hold the mutex.
+func (s *ServerImplementation) enqueueWebhook(delivery webhookDelivery) {
+	select {
+	case s.webhookStore.queue <- delivery:
+	default:
+		message := "delivery queue is full"
+		s.logWebhookDelivery(delivery, api.WebhookDelivery{Error: &message})
+	}
+}
+
+// logWebhookDelivery records a delivery attempt. The caller must hold the mutex.
+func (s *ServerImplementation) logWebhookDelivery(delivery webhookDelivery, entry api.WebhookDelivery) {
+	entry.Id = delivery.Id
+	entry.EventId = delivery.Event.Id
+	entry.EventType = delivery.EventType
+	entry.Attempt = delivery.Attempt
+	entry.AttemptedAt = time.Now()
+	log := append(s.webhookStore.deliveries[delivery.SubscriptionId], entry)
+	if len(log) > webhookDeliveryLogSize {
+		log = log[len(log)-webhookDeliveryLogSize:]
+	}
+	s.webhookStore.deliveries[delivery.SubscriptionId] = log
+}
+
+func (s *ServerImplementation) deliverWebhook(ctx context.Context, logger echo.Logger, client *http.Client, delivery webhookDelivery) {
+	s.webhookStore.mutex.Lock()
+	subscription, ok := s.webhookStore.subscriptions[delivery.SubscriptionId]
+	s.webhookStore.mutex.Unlock()
+	if !ok {
+		// Deleted in the meantime
+		return
+	}
+
+	entry := api.WebhookDelivery{}
+	err := func() error {
+		request, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.Url, bytes.NewReader(delivery.Payload))
+		if err != nil {
+			return err
+		}
+		request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		request.Header.Set("X-SCS-Event", string(delivery.EventType))
+		request.Header.Set("X-SCS-Delivery", delivery.Id)
+		request.Header.Set("X-SCS-Signature-256", signPayload(subscription.Secret, delivery.Payload))
+		response, err := client.Do(request)
+		if err != nil {
+			return err
+		}
+		response.Body.Close()
+		entry.StatusCode = &response.StatusCode
+		if response.StatusCode < 200 || response.StatusCode > 299 {
+			return fmt.Errorf("unexpected status code %d", response.StatusCode)
+		}
+		return nil
+	}()
+	if err != nil {
+		message := err.Error()
+		entry.Error = &message
+	} else {
+		entry.Success = true
+	}
+
+	s.webhookStore.mutex.Lock()
+	defer s.webhookStore.mutex.Unlock()
+	s.logWebhookDelivery(delivery, entry)
+	if err == nil || delivery.Attempt >= webhookMaxAttempts {
+		if err != nil {
+			logger.Warnf(`Giving up delivering event %d to webhook "%s": %s`, delivery.Event.Id, delivery.SubscriptionId, err)
+		}
+		return
+	}
+	backoff := webhookInitialBackoff << (delivery.Attempt - 1)
+	delivery.Attempt++
+	time.AfterFunc(backoff, func() {
+		s.webhookStore.mutex.Lock()
+		defer s.webhookStore.mutex.Unlock()
+		s.enqueueWebhook(delivery)
+	})
+}
+
+func (s *ServerImplementation) GetWebhooks(ctx echo.Context) error {
+	if err := s.authorize(ctx); err != nil {
+		return err
+	}
+	s.webhookStore.mutex.Lock()
+	defer s.webhookStore.mutex.Unlock()
+	subscriptions := []api.WebhookSubscription{}
+	for _, subscription := range s.webhookStore.subscriptions {
+		subscriptions = append(subscriptions, subscription.WebhookSubscription)
+	}
+	return ctx.JSON(200, subscriptions)
+}
+
+func (s *ServerImplementation) CreateWebhook(ctx echo.Context) error {
+	if err := s.authorize(ctx); err != nil {
+		return err
+	}
+	var body api.CreateWebhookJSONRequestBody
+	if err := ctx.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if parsed, err := url.Parse(body.Url); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return echo.NewHTTPError(http.StatusBadRequest, "expected url to be an absolute http(s) URL")
+	}
+	if body.Secret == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "expected secret")
+	}
+	subscription := &webhookSubscription{
+		WebhookSubscription: api.WebhookSubscription{
+			Id:         newId(),
+			Url:        body.Url,
+			Events:     []api.WebhookEventType{},
+			Components: []api.Id{},
+			CreatedAt:  time.Now(),
+		},
+		Secret: body.Secret,
+	}
+	if body.Events != nil {
+		subscription.Events = *body.Events
+	}
+	if body.Components != nil {
+		subscription.Components = *body.Components
+	}
+
+	s.webhookStore.mutex.Lock()
+	defer s.webhookStore.mutex.Unlock()
+	if s.webhookStore.subscriptions == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "webhooks are not running")
+	}
+	s.webhookStore.subscriptions[subscription.Id] = subscription
+	if err := s.saveWebhooks(); err != nil {
+		delete(s.webhookStore.subscriptions, subscription.Id)
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(500)
+	}
+	return ctx.JSON(201, subscription.WebhookSubscription)
+}
+
+func (s *ServerImplementation) GetWebhook(ctx echo.Context, webhookId string) error {
+	if err := s.authorize(ctx); err != nil {
+		return err
+	}
+	s.webhookStore.mutex.Lock()
+	defer s.webhookStore.mutex.Unlock()
+	subscription, ok := s.webhookStore.subscriptions[webhookId]
+	if !ok {
+		return echo.NewHTTPError(404)
+	}
+	return ctx.JSON(200, subscription.WebhookSubscription)
+}
+
+func (s *ServerImplementation) DeleteWebhook(ctx echo.Context, webhookId string) error {
+	if err := s.authorize(ctx); err != nil {
+		return err
+	}
+	s.webhookStore.mutex.Lock()
+	defer s.webhookStore.mutex.Unlock()
+	subscription, ok := s.webhookStore.subscriptions[webhookId]
+	if !ok {
+		return echo.NewHTTPError(404)
+	}
+	delete(s.webhookStore.subscriptions, webhookId)
+	if err := s.saveWebhooks(); err != nil {
+		s.webhookStore.subscriptions[webhookId] = subscription
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(500)
+	}
+	delete(s.webhookStore.deliveries, webhookId)
+	return ctx.NoContent(204)
+}
+
+func (s *ServerImplementation) GetWebhookDeliveries(ctx echo.Context, webhookId string) error {
+	if err := s.authorize(ctx); err != nil {
+		return err
+	}
+	s.webhookStore.mutex.Lock()
+	defer s.webhookStore.mutex.Unlock()
+	if _, ok := s.webhookStore.subscriptions[webhookId]; !ok {
+		return echo.NewHTTPError(404)
+	}
+	deliveries := append([]api.WebhookDelivery{}, s.webhookStore.deliveries[webhookId]...)
+	return ctx.JSON(200, deliveries)
+}
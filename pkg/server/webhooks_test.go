@@ -0,0 +1,45 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/joshmue/scs-status-page-openapi/pkg/api"
+)
+
+func TestNotificationType(t *testing.T) {
+	s := &ServerImplementation{LastPhase: "Resolved"}
+	incident := func(phase string, title string) api.Incident {
+		return api.Incident{Id: "1", Title: title, Phase: phase}
+	}
+	tests := []struct {
+		name     string
+		before   *api.Incident
+		after    api.Incident
+		expected api.WebhookEventType
+		notified bool
+	}{
+		{"created", nil, incident("", "Outage"), api.IncidentCreated, true},
+		{"phase set for the first time", &api.Incident{Id: "1", Title: "Outage"}, incident("Investigating", "Outage"), api.IncidentPhaseChanged, true},
+		{"phase changed", &api.Incident{Id: "1", Title: "Outage", Phase: "Investigating"}, incident("Identified", "Outage"), api.IncidentPhaseChanged, true},
+		{"resolved", &api.Incident{Id: "1", Title: "Outage", Phase: "Identified"}, incident("Resolved", "Outage"), api.IncidentResolved, true},
+		{"resolved without phase before", &api.Incident{Id: "1", Title: "Outage"}, incident("Resolved", "Outage"), api.IncidentResolved, true},
+		{"title changed", &api.Incident{Id: "1", Title: "Outage", Phase: "Identified"}, incident("Identified", "API outage"), "", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			previous := &Snapshot{ComponentStatuses: map[string]string{}}
+			if test.before != nil {
+				previous.Incidents = []api.Incident{*test.before}
+			}
+			next := &Snapshot{Incidents: []api.Incident{test.after}, ComponentStatuses: map[string]string{}}
+			events := s.diffSnapshots(previous, next)
+			if len(events) != 1 {
+				t.Fatalf("expected 1 event; got %+v", events)
+			}
+			eventType, notified := NotificationType(events[0])
+			if eventType != test.expected || notified != test.notified {
+				t.Errorf("expected %q (%t); got %q (%t)", test.expected, test.notified, eventType, notified)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/joshmue/scs-status-page-openapi/pkg/api"
+	"github.com/labstack/echo/v4"
+	"golang.org/x/net/websocket"
+)
+
+// Message types of the WebSocket subscription protocol.
+const (
+	// Sent by clients
+	WebsocketSubscribe = "subscribe"
+	WebsocketPing      = "ping"
+	// Sent by the server
+	WebsocketSubscribed = "subscribed"
+	WebsocketEvent      = "event"
+	WebsocketHeartbeat  = "heartbeat"
+	WebsocketPong       = "pong"
+	WebsocketError      = "error"
+)
+
+const (
+	// websocketHeartbeatInterval is the interval of heartbeat messages.
+	websocketHeartbeatInterval = 30 * time.Second
+	// websocketWriteTimeout bounds how long a single message may take to be sent.
+	websocketWriteTimeout = 10 * time.Second
+	// websocketMaxMessageSize bounds the size of messages sent by clients.
+	websocketMaxMessageSize = 64 << 10
+)
+
+// WebsocketMessage is a message of the WebSocket subscription protocol.
+//
+// Clients send "subscribe" messages to replace their filter, where empty lists
+// match everything, and "ping" messages, which are answered by "pong".
+// The server confirms filters with "subscribed", forwards matching events as
+// "event" and sends a "heartbeat" periodically.
+type WebsocketMessage struct {
+	Type        string                   `json:"type"`
+	Components  []api.Id                 `json:"components,omitempty"`
+	ImpactTypes []api.IncidentImpactType `json:"impactTypes,omitempty"`
+	Event       *Event                   `json:"event,omitempty"`
+	Time        *time.Time               `json:"time,omitempty"`
+	Message     string                   `json:"message,omitempty"`
+}
+
+// websocketFilter selects events by affected components and impact types.
+type websocketFilter struct {
+	Components  []api.Id
+	ImpactTypes []api.IncidentImpactType
+}
+
+func (f *websocketFilter) matches(event Event) bool {
+	if event.Incident != nil {
+		return incidentAffectsAny(*event.Incident, f.Components) && f.matchesImpactType(event.Incident.ImpactType)
+	}
+	if event.ComponentId != "" {
+		if len(f.Components) > 0 && !contains(f.Components, event.ComponentId) {
+			return false
+		}
+		return f.matchesImpactType(event.Status) || f.matchesImpactType(event.PreviousStatus)
+	}
+	return true
+}
+
+func (f *websocketFilter) matchesImpactType(impactType api.IncidentImpactType) bool {
+	return len(f.ImpactTypes) == 0 || contains(f.ImpactTypes, impactType)
+}
+
+func contains(list []string, item string) bool {
+	for i := range list {
+		if list[i] == item {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisterWebsocketHandlers adds a WebSocket endpoint for subscribing to filtered events.
+func (s *ServerImplementation) RegisterWebsocketHandlers(router api.EchoRouter) {
+	router.GET("/ws", s.GetWebsocket)
+}
+
+// GetWebsocket accepts clients from any origin, including non-browser clients sending
+// no "Origin" header, as the stream is public and does not rely on credentials.
+func (s *ServerImplementation) GetWebsocket(ctx echo.Context) error {
+	websocket.Server{
+		Handshake: func(config *websocket.Config, request *http.Request) error {
+			// An invalid or missing origin only leaves config.Origin unset
+			config.Origin, _ = websocket.Origin(config, request)
+			return nil
+		},
+		Handler: func(conn *websocket.Conn) {
+			defer conn.Close()
+			conn.MaxPayloadBytes = websocketMaxMessageSize
+			s.serveWebsocket(ctx.Logger(), conn)
+		},
+	}.ServeHTTP(ctx.Response(), ctx.Request())
+	return nil
+}
+
+func (s *ServerImplementation) serveWebsocket(logger echo.Logger, conn *websocket.Conn) {
+	subscriber, _, _ := s.events.subscribe(nil)
+	defer s.events.unsubscribe(subscriber)
+
+	// Only this goroutine writes; incoming messages are passed over
+	requests := make(chan WebsocketMessage)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		defer close(requests)
+		for {
+			var request WebsocketMessage
+			if err := websocket.JSON.Receive(conn, &request); err != nil {
+				return
+			}
+			select {
+			case requests <- request:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	send := func(message WebsocketMessage) bool {
+		conn.SetWriteDeadline(time.Now().Add(websocketWriteTimeout))
+		if err := websocket.JSON.Send(conn, message); err != nil {
+			logger.Debug(err)
+			return false
+		}
+		return true
+	}
+
+	filter := websocketFilter{}
+	heartbeat := time.NewTicker(websocketHeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		var ok bool
+		select {
+		case request, open := <-requests:
+			if !open {
+				return
+			}
+			switch request.Type {
+			case WebsocketSubscribe:
+				filter = websocketFilter{Components: request.Components, ImpactTypes: request.ImpactTypes}
+				ok = send(WebsocketMessage{Type: WebsocketSubscribed, Components: filter.Components, ImpactTypes: filter.ImpactTypes})
+			case WebsocketPing:
+				ok = send(WebsocketMessage{Type: WebsocketPong})
+			default:
+				ok = send(WebsocketMessage{Type: WebsocketError, Message: "unknown message type: " + request.Type})
+			}
+		case event, open := <-subscriber:
+			if !open {
+				// The client did not keep up with events
+				send(WebsocketMessage{Type: WebsocketError, Message: "too slow, events were dropped"})
+				return
+			}
+			if !filter.matches(event) {
+				continue
+			}
+			ok = send(WebsocketMessage{Type: WebsocketEvent, Event: &event})
+		case now := <-heartbeat.C:
+			ok = send(WebsocketMessage{Type: WebsocketHeartbeat, Time: &now})
+		}
+		if !ok {
+			return
+		}
+	}
+}
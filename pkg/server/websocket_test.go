@@ -0,0 +1,85 @@
+package server
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/net/websocket"
+)
+
+func websocketServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	s := &ServerImplementation{}
+	e := echo.New()
+	s.RegisterWebsocketHandlers(e)
+	server := httptest.NewServer(e)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWebsocketWithoutOrigin(t *testing.T) {
+	server := websocketServer(t)
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	// Non-browser clients send no "Origin" header
+	request := "GET /ws HTTP/1.1\r\n" +
+		"Host: " + strings.TrimPrefix(server.URL, "http://") + "\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n" +
+		"Sec-WebSocket-Version: 13\r\n\r\n"
+	if _, err := conn.Write([]byte(request)); err != nil {
+		t.Fatal(err)
+	}
+	response, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.StatusCode != http.StatusSwitchingProtocols {
+		t.Errorf("expected the handshake to succeed; got %s", response.Status)
+	}
+}
+
+func TestWebsocketPing(t *testing.T) {
+	server := websocketServer(t)
+	conn, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", "", "https://dashboard.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := websocket.JSON.Send(conn, WebsocketMessage{Type: WebsocketPing}); err != nil {
+		t.Fatal(err)
+	}
+	var response WebsocketMessage
+	if err := websocket.JSON.Receive(conn, &response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Type != WebsocketPong {
+		t.Errorf("expected pong; got %+v", response)
+	}
+}
+
+func TestWebsocketMessageSizeLimit(t *testing.T) {
+	server := websocketServer(t)
+	conn, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", "", "https://dashboard.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	oversized := `{"type": "subscribe", "components": ["` + strings.Repeat("a", websocketMaxMessageSize) + `"]}`
+	if _, err := conn.Write([]byte(oversized)); err != nil {
+		t.Fatal(err)
+	}
+	var response WebsocketMessage
+	if err := websocket.JSON.Receive(conn, &response); err == nil {
+		t.Errorf("expected the connection to be closed; got %+v", response)
+	}
+}